@@ -0,0 +1,85 @@
+package merkletree
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// AuditReport result of audit sampling
+type AuditReport struct {
+	// Sampled indexes of leaves, in ascending order
+	Sampled []uint64
+
+	// Failed indexes of leaves whose proofs failed, in ascending order
+	Failed []uint64
+}
+
+// OK returns true if all sampled proofs passed
+func (report *AuditReport) OK() bool {
+	return report != nil && len(report.Failed) == 0
+}
+
+// AuditSample picks n distinct random leaves, generates & verifies their
+// proofs against the root. All leaves are audited if n >= number of leaves,
+// which excludes the synthetic duplicate if configured by WithLeafCount.
+// rng is used to pick leaves, a time-seeded one is used if it's nil. The
+// audit is aborted if the context configured by WithContext is done.
+func (tree *Tree) AuditSample(n int, h IHashFunc, rng *rand.Rand, opt ...OptionFunc) (*AuditReport, error) {
+	if tree == nil || tree.Height() == 0 {
+		return nil, errors.New("tree is empty")
+	} else if n <= 0 {
+		return nil, errors.New("invalid sample size")
+	}
+
+	opts := NewOptions(opt...)
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	count, err := tree.leafCount(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := new(AuditReport)
+	if uint64(n) >= count {
+		for x := uint64(0); x < count; x++ {
+			report.Sampled = append(report.Sampled, x)
+		}
+	} else {
+		picked := make(map[uint64]bool, n)
+		for len(picked) < n {
+			x := uint64(rng.Int63n(int64(count)))
+			if !picked[x] {
+				picked[x] = true
+				report.Sampled = append(report.Sampled, x)
+			}
+		}
+		sort.Slice(report.Sampled, func(i, j int) bool {
+			return report.Sampled[i] < report.Sampled[j]
+		})
+	}
+
+	for _, x := range report.Sampled {
+		if err := opts.Context.Err(); err != nil {
+			return nil, err
+		}
+
+		merklePath, err := tree.PathForLeaf(x, opt...)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := tree.Prove(&merklePath, (*tree)[0][x], h, opt...)
+		if ctxErr := opts.Context.Err(); ctxErr != nil {
+			return nil, ctxErr
+		} else if err != nil || !result {
+			report.Failed = append(report.Failed, x)
+		}
+	}
+
+	return report, nil
+}
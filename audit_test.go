@@ -0,0 +1,47 @@
+package merkletree_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Audit random leaves of tree
+func TestTree_AuditSample(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, _, err := mockLeaves(100).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test consistent tree
+	report, err := tree.AuditSample(10, h, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Report=", report)
+	assert.Equal(t, 10, len(report.Sampled))
+	assert.True(t, report.OK())
+
+	// Test audit all leaves of corrupted tree
+	(*tree)[1][3] = append([]byte{}, badHash...)
+	report, err = tree.AuditSample(1000, h, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Report=", report)
+	assert.Equal(t, 100, len(report.Sampled))
+	assert.False(t, report.OK())
+	assert.Equal(t, []uint64{4, 5}, report.Failed)
+
+	// Test invalid sample size
+	_, err = tree.AuditSample(0, h, nil)
+	assert.NotNil(t, err)
+
+	// Test invalid tree
+	var invalidTree1 *merkletree.Tree
+	_, err = invalidTree1.AuditSample(1, h, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+package merkletree
+
+import (
+	"hash"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// PooledHashFunc hash function which reuses hash states from a pool, safe
+// for concurrent use
+type PooledHashFunc struct {
+	pool sync.Pool
+}
+
+// NewPooledHashFunc returns a new pooled hash function of provider
+func NewPooledHashFunc(provider HashProvider) *PooledHashFunc {
+	h := new(PooledHashFunc)
+	h.pool.New = func() interface{} {
+		return provider()
+	}
+
+	return h
+}
+
+// Hash returns message digest
+func (h *PooledHashFunc) Hash(msg []byte) ([]byte, error) {
+	state := h.pool.Get().(hash.Hash)
+	defer h.pool.Put(state)
+
+	state.Reset()
+	if _, err := state.Write(msg); err != nil {
+		return nil, err
+	}
+
+	return state.Sum(nil), nil
+}
+
+// HashReader returns digest of message read from r until EOF
+func (h *PooledHashFunc) HashReader(r io.Reader) ([]byte, error) {
+	state := h.pool.Get().(hash.Hash)
+	defer h.pool.Put(state)
+
+	state.Reset()
+	if _, err := io.Copy(state, r); err != nil {
+		return nil, err
+	}
+
+	return state.Sum(nil), nil
+}
+
+// Size returns digest size in bytes
+func (h *PooledHashFunc) Size() int {
+	state := h.pool.Get().(hash.Hash)
+	defer h.pool.Put(state)
+
+	return state.Size()
+}
+
+// BuildTrees build many independent trees concurrently by a shared pool of
+// workers, returns trees & roots in the order of set. If the hash function
+// is a *HashFunc, its hash states are pooled across workers.
+func BuildTrees(set []Leaves, opt ...OptionFunc) ([]*Tree, []*Root, error) {
+	opts := NewOptions(opt...)
+
+	if hashFunc, ok := opts.HashFunc.(*HashFunc); ok {
+		opt = append(opt, WithHashFunc(NewPooledHashFunc(hashFunc.Provider)))
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(set) {
+		workers = len(set)
+	}
+
+	trees := make([]*Tree, len(set))
+	roots := make([]*Root, len(set))
+	errs := make([]error, len(set))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				trees[index], roots[index], errs[index] = set[index].BuildTree(opt...)
+			}
+		}()
+	}
+
+	for index := range set {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return trees, roots, nil
+}
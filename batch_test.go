@@ -0,0 +1,64 @@
+package merkletree_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build many trees concurrently
+func TestBuildTrees(t *testing.T) {
+	var set []merkletree.Leaves
+	for size := 1; size <= 64; size++ {
+		set = append(set, *mockLeaves(size))
+	}
+
+	h := &merkletree.HashFunc{Provider: sha256.New}
+	trees, roots, err := merkletree.BuildTrees(set, merkletree.WithHashFunc(h), merkletree.WithWorkers(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(set), len(trees))
+	assert.Equal(t, len(set), len(roots))
+
+	for i := range set {
+		tree, root, err := mockLeaves(i + 1).BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, *tree, *trees[i])
+		assert.Equal(t, root.Hash, roots[i].Hash)
+	}
+
+	// Test invalid leaves in set
+	set = append(set, merkletree.Leaves{})
+	_, _, err = merkletree.BuildTrees(set)
+	if err != nil {
+		t.Log("leaves is empty, build trees failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test empty set
+	trees, roots, err = merkletree.BuildTrees(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, trees)
+	assert.Empty(t, roots)
+}
+
+// Hash with pooled hash func
+func TestPooledHashFunc_Hash(t *testing.T) {
+	h := merkletree.NewPooledHashFunc(sha256.New)
+	assert.Equal(t, 32, h.Size())
+
+	for i := 0; i < 3; i++ {
+		digest, err := h.Hash([]byte("你好"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, goodHash, digest)
+	}
+}
@@ -0,0 +1,325 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxPartialMerkleTreeTransactions is the max number of transactions of a
+// partial merkle tree, as limited by the max block weight
+const MaxPartialMerkleTreeTransactions = 4000000 / 240
+
+// PartialMerkleTree is the partial merkle tree of Bitcoin merkleblock
+// messages (BIP 37): the hashes & flag bits needed to prove a subset of the
+// transactions of a block. Hashes are in internal byte order, which is the
+// reverse of the displayed order.
+type PartialMerkleTree struct {
+	// Transactions is the number of transactions in the block
+	Transactions uint32
+
+	// Hashes in depth-first order
+	Hashes []Hash
+
+	// Flags in depth-first order
+	Flags []bool
+}
+
+// NewPartialMerkleTree returns partial merkle tree of txids, which proves
+// the txids whose matches is true
+func NewPartialMerkleTree(txids []Hash, matches []bool) (*PartialMerkleTree, error) {
+	if len(txids) == 0 {
+		return nil, errors.New("not found transaction")
+	} else if len(txids) != len(matches) {
+		return nil, errors.New("txids & matches mismatch")
+	} else if len(txids) > MaxPartialMerkleTreeTransactions {
+		return nil, errors.New("too many transactions")
+	}
+
+	pmt := &PartialMerkleTree{
+		Transactions: uint32(len(txids)),
+	}
+
+	if err := pmt.traverseAndBuild(pmt.height(), 0, txids, matches); err != nil {
+		return nil, err
+	}
+
+	return pmt, nil
+}
+
+// ExtractMatches verify the structure of the partial merkle tree, returns
+// merkle root, matched txids & their indexes in the block. The root must be
+// compared with the merkle root of the block header by the caller.
+func (pmt *PartialMerkleTree) ExtractMatches() (Hash, []Hash, []uint32, error) {
+	if pmt == nil || pmt.Transactions == 0 {
+		return nil, nil, nil, errors.New("not found transaction")
+	} else if pmt.Transactions > MaxPartialMerkleTreeTransactions {
+		return nil, nil, nil, errors.New("too many transactions")
+	} else if len(pmt.Hashes) > int(pmt.Transactions) {
+		return nil, nil, nil, errors.New("more hashes than transactions")
+	} else if len(pmt.Flags) < len(pmt.Hashes) {
+		return nil, nil, nil, errors.New("fewer flag bits than hashes")
+	}
+
+	extractor := &pmtExtractor{pmt: pmt}
+	root, err := extractor.traverse(pmt.height(), 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if (extractor.bitsUsed+7)/8 != (len(pmt.Flags)+7)/8 {
+		return nil, nil, nil, errors.New("not all flag bits consumed")
+	} else if extractor.hashesUsed != len(pmt.Hashes) {
+		return nil, nil, nil, errors.New("not all hashes consumed")
+	}
+
+	return root, extractor.matches, extractor.indexes, nil
+}
+
+// MarshalBinary returns bytes of partial merkle tree in the merkleblock
+// encoding: transaction count, hashes & flag bytes
+func (pmt *PartialMerkleTree) MarshalBinary() ([]byte, error) {
+	if pmt == nil {
+		return nil, errors.New("partial merkle tree is empty")
+	}
+
+	var buf bytes.Buffer
+
+	var count [4]byte
+	binary.LittleEndian.PutUint32(count[:], pmt.Transactions)
+	buf.Write(count[:])
+
+	writeCompactSize(&buf, uint64(len(pmt.Hashes)))
+	for _, hash := range pmt.Hashes {
+		if len(hash) != 32 {
+			return nil, fmt.Errorf("invalid hash size %d", len(hash))
+		}
+		buf.Write(hash)
+	}
+
+	flags := make([]byte, (len(pmt.Flags)+7)/8)
+	for i, flag := range pmt.Flags {
+		if flag {
+			flags[i/8] |= 1 << uint(i%8)
+		}
+	}
+	writeCompactSize(&buf, uint64(len(flags)))
+	buf.Write(flags)
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decode partial merkle tree in the merkleblock encoding
+func (pmt *PartialMerkleTree) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var count [4]byte
+	if _, err := io.ReadFull(r, count[:]); err != nil {
+		return err
+	}
+
+	hashCount, err := readCompactSize(r)
+	if err != nil {
+		return err
+	} else if hashCount > uint64(r.Len())/32 {
+		return errors.New("invalid hash count")
+	}
+
+	hashes := make([]Hash, hashCount)
+	for i := range hashes {
+		hashes[i] = make(Hash, 32)
+		if _, err := io.ReadFull(r, hashes[i]); err != nil {
+			return err
+		}
+	}
+
+	flagCount, err := readCompactSize(r)
+	if err != nil {
+		return err
+	} else if flagCount != uint64(r.Len()) {
+		return errors.New("invalid flag byte count")
+	}
+
+	flagBytes := make([]byte, flagCount)
+	if _, err := io.ReadFull(r, flagBytes); err != nil {
+		return err
+	}
+
+	flags := make([]bool, len(flagBytes)*8)
+	for i := range flags {
+		flags[i] = flagBytes[i/8]&(1<<uint(i%8)) != 0
+	}
+
+	pmt.Transactions = binary.LittleEndian.Uint32(count[:])
+	pmt.Hashes = hashes
+	pmt.Flags = flags
+
+	return nil
+}
+
+// height returns height of the tree
+func (pmt *PartialMerkleTree) height() uint {
+	height := uint(0)
+	for pmt.width(height) > 1 {
+		height++
+	}
+
+	return height
+}
+
+// width returns number of nodes at height
+func (pmt *PartialMerkleTree) width(height uint) uint64 {
+	return (uint64(pmt.Transactions) + (1 << height) - 1) >> height
+}
+
+// calcHash returns hash of node (height, pos) from all txids
+func (pmt *PartialMerkleTree) calcHash(height uint, pos uint64, txids []Hash) (Hash, error) {
+	if height == 0 {
+		return txids[pos], nil
+	}
+
+	left, err := pmt.calcHash(height-1, pos*2, txids)
+	if err != nil {
+		return nil, err
+	}
+
+	right := left
+	if pos*2+1 < pmt.width(height-1) {
+		if right, err = pmt.calcHash(height-1, pos*2+1, txids); err != nil {
+			return nil, err
+		}
+	}
+
+	return hashPair(ProfileBitcoin.HashFunc, nil, left, right, false)
+}
+
+// traverseAndBuild append flags & hashes of node (height, pos) depth-first
+func (pmt *PartialMerkleTree) traverseAndBuild(height uint, pos uint64, txids []Hash, matches []bool) error {
+	parentOfMatch := false
+	for p := pos << height; p < (pos+1)<<height && p < uint64(pmt.Transactions); p++ {
+		parentOfMatch = parentOfMatch || matches[p]
+	}
+	pmt.Flags = append(pmt.Flags, parentOfMatch)
+
+	if height == 0 || !parentOfMatch {
+		hash, err := pmt.calcHash(height, pos, txids)
+		if err != nil {
+			return err
+		}
+		pmt.Hashes = append(pmt.Hashes, hash)
+		return nil
+	}
+
+	if err := pmt.traverseAndBuild(height-1, pos*2, txids, matches); err != nil {
+		return err
+	}
+	if pos*2+1 < pmt.width(height-1) {
+		return pmt.traverseAndBuild(height-1, pos*2+1, txids, matches)
+	}
+
+	return nil
+}
+
+// pmtExtractor state of extracting matches from partial merkle tree
+type pmtExtractor struct {
+	pmt        *PartialMerkleTree
+	bitsUsed   int
+	hashesUsed int
+	matches    []Hash
+	indexes    []uint32
+}
+
+// traverse returns hash of node (height, pos), collect matches depth-first
+func (e *pmtExtractor) traverse(height uint, pos uint64) (Hash, error) {
+	if e.bitsUsed >= len(e.pmt.Flags) {
+		return nil, errors.New("overflowed flag bits")
+	}
+	parentOfMatch := e.pmt.Flags[e.bitsUsed]
+	e.bitsUsed++
+
+	if height == 0 || !parentOfMatch {
+		if e.hashesUsed >= len(e.pmt.Hashes) {
+			return nil, errors.New("overflowed hashes")
+		}
+		hash := e.pmt.Hashes[e.hashesUsed]
+		e.hashesUsed++
+
+		if height == 0 && parentOfMatch {
+			e.matches = append(e.matches, hash)
+			e.indexes = append(e.indexes, uint32(pos))
+		}
+		return hash, nil
+	}
+
+	left, err := e.traverse(height-1, pos*2)
+	if err != nil {
+		return nil, err
+	}
+
+	right := left
+	if pos*2+1 < e.pmt.width(height-1) {
+		if right, err = e.traverse(height-1, pos*2+1); err != nil {
+			return nil, err
+		}
+
+		// Identical children of a node with both children is invalid,
+		// see CVE-2012-2459
+		if bytes.Equal(left, right) {
+			return nil, errors.New("invalid duplicate children")
+		}
+	}
+
+	return hashPair(ProfileBitcoin.HashFunc, nil, left, right, false)
+}
+
+// writeCompactSize writes v in Bitcoin CompactSize encoding
+func writeCompactSize(buf *bytes.Buffer, v uint64) {
+	var tmp [8]byte
+	switch {
+	case v < 0xfd:
+		buf.WriteByte(byte(v))
+	case v <= 0xffff:
+		buf.WriteByte(0xfd)
+		binary.LittleEndian.PutUint16(tmp[:], uint16(v))
+		buf.Write(tmp[:2])
+	case v <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		binary.LittleEndian.PutUint32(tmp[:], uint32(v))
+		buf.Write(tmp[:4])
+	default:
+		buf.WriteByte(0xff)
+		binary.LittleEndian.PutUint64(tmp[:], v)
+		buf.Write(tmp[:])
+	}
+}
+
+// readCompactSize reads v in Bitcoin CompactSize encoding
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	var tmp [8]byte
+	switch prefix {
+	case 0xfd:
+		if _, err := io.ReadFull(r, tmp[:2]); err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint16(tmp[:2])), nil
+	case 0xfe:
+		if _, err := io.ReadFull(r, tmp[:4]); err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint32(tmp[:4])), nil
+	case 0xff:
+		if _, err := io.ReadFull(r, tmp[:]); err != nil {
+			return 0, err
+		}
+		return binary.LittleEndian.Uint64(tmp[:]), nil
+	default:
+		return uint64(prefix), nil
+	}
+}
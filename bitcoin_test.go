@@ -0,0 +1,152 @@
+package merkletree_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockTxids returns n txids
+func mockTxids(n int) []merkletree.Hash {
+	txids := make([]merkletree.Hash, 0, n)
+	for i := 0; i < n; i++ {
+		txid := sha256.Sum256([]byte{byte(i), byte(i >> 8)})
+		txids = append(txids, txid[:])
+	}
+
+	return txids
+}
+
+// Build & extract partial merkle tree, compare root with BuildTree
+func TestPartialMerkleTree(t *testing.T) {
+	for size := 1; size <= 40; size++ {
+		txids := mockTxids(size)
+
+		var leaves merkletree.Leaves
+		for _, txid := range txids {
+			leaves.Add(&merkletree.Leaf{Hash: txid})
+		}
+		_, root, err := leaves.BuildTree(merkletree.WithProfile(merkletree.ProfileBitcoin), merkletree.WithSkipHash(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Match every third transaction
+		matches := make([]bool, size)
+		var expectedMatches []merkletree.Hash
+		var expectedIndexes []uint32
+		for i := 0; i < size; i += 3 {
+			matches[i] = true
+			expectedMatches = append(expectedMatches, txids[i])
+			expectedIndexes = append(expectedIndexes, uint32(i))
+		}
+
+		pmt1, err := merkletree.NewPartialMerkleTree(txids, matches)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Round trip of the merkleblock encoding
+		data, err := pmt1.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pmt2 merkletree.PartialMerkleTree
+		if err := pmt2.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+
+		pmtRoot, matched, indexes, err := pmt2.ExtractMatches()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expectedMatches, matched, "size=%d", size)
+		assert.Equal(t, expectedIndexes, indexes, "size=%d", size)
+
+		// A single transaction is the root itself in Bitcoin
+		if size == 1 {
+			assert.Equal(t, txids[0], []byte(pmtRoot))
+			continue
+		}
+		assert.Equal(t, root.Hash, []byte(pmtRoot), "size=%d", size)
+	}
+}
+
+// Extract matches of invalid partial merkle tree
+func TestPartialMerkleTree_Invalid(t *testing.T) {
+	txids := mockTxids(7)
+	matches := []bool{false, true, false, false, false, true, false}
+
+	pmt, err := merkletree.NewPartialMerkleTree(txids, matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test tampered hash, root changes
+	root, _, _, err := pmt.ExtractMatches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pmt.Hashes[0] = append(merkletree.Hash{}, badHash...)
+	tamperedRoot, _, _, err := pmt.ExtractMatches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, root, tamperedRoot)
+
+	// Test missing hashes
+	pmt.Hashes = pmt.Hashes[:len(pmt.Hashes)-1]
+	_, _, _, err = pmt.ExtractMatches()
+	if err != nil {
+		t.Log("hashes are missing, extract failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	// Test extra flag bytes
+	pmt, err = merkletree.NewPartialMerkleTree(txids, matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pmt.Flags = append(pmt.Flags, make([]bool, 16)...)
+	_, _, _, err = pmt.ExtractMatches()
+	assert.NotNil(t, err)
+
+	// Test invalid arguments
+	_, err = merkletree.NewPartialMerkleTree(nil, nil)
+	assert.NotNil(t, err)
+	_, err = merkletree.NewPartialMerkleTree(txids, matches[:1])
+	assert.NotNil(t, err)
+
+	// Test truncated data
+	pmt, err = merkletree.NewPartialMerkleTree(txids, matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := pmt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(data); i++ {
+		var decoded merkletree.PartialMerkleTree
+		assert.NotNil(t, decoded.UnmarshalBinary(data[:i]), "length=%d", i)
+	}
+}
+
+// Extract matches of partial merkle tree with duplicate children
+func TestPartialMerkleTree_DuplicateChildren(t *testing.T) {
+	txids := mockTxids(4)
+	txids[3] = txids[2]
+
+	pmt, err := merkletree.NewPartialMerkleTree(txids, []bool{true, true, true, true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, err = pmt.ExtractMatches()
+	if err != nil {
+		t.Log("children are identical, extract failed as expected")
+	}
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,78 @@
+package merkletree
+
+import (
+	"fmt"
+)
+
+// BudgetError is returned when the estimated memory of a build exceeds the
+// configured budget
+type BudgetError struct {
+	// Estimated memory in bytes
+	Estimated uint64
+
+	// Budget in bytes
+	Budget uint64
+}
+
+// Error returns error message
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("estimated memory %d bytes exceeds budget %d bytes", e.Estimated, e.Budget)
+}
+
+// EstimateMemory returns estimated memory in bytes of building a tree from
+// the leaves, which is leaf count * hash size * 2 plus size of payloads
+func (obj *Leaves) EstimateMemory(opt ...OptionFunc) (uint64, error) {
+	opts := NewOptions(opt...)
+
+	size, err := hashSize(opts.HashFunc)
+	if err != nil {
+		return 0, err
+	}
+
+	count := uint64(obj.Length())
+	if count%2 == 1 {
+		count++
+	}
+
+	estimated := count * uint64(size) * 2
+	for i := 0; i < obj.Length(); i++ {
+		estimated += uint64(len((*obj)[i].Payload))
+	}
+
+	return estimated, nil
+}
+
+// checkBudget returns *BudgetError if estimated memory exceeds the budget
+func (obj *Leaves) checkBudget(opts Options) error {
+	if opts.MemoryBudget == 0 {
+		return nil
+	}
+
+	estimated, err := obj.EstimateMemory(WithHashFunc(opts.HashFunc))
+	if err != nil {
+		return err
+	}
+
+	if estimated > opts.MemoryBudget {
+		return &BudgetError{
+			Estimated: estimated,
+			Budget:    opts.MemoryBudget,
+		}
+	}
+
+	return nil
+}
+
+// hashSize returns digest size of hash function
+func hashSize(h IHashFunc) (int, error) {
+	if sizer, ok := h.(interface{ Size() int }); ok {
+		return sizer.Size(), nil
+	}
+
+	digest, err := h.Hash(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(digest), nil
+}
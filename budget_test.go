@@ -0,0 +1,55 @@
+package merkletree_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Estimate memory of building tree
+func TestLeaves_EstimateMemory(t *testing.T) {
+	leaves := MockLeaves.Clone()
+
+	estimated, err := leaves.EstimateMemory(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Estimated=", estimated)
+
+	payloadSize := uint64(0)
+	for _, leaf := range *leaves {
+		payloadSize += uint64(len(leaf.Payload))
+	}
+	assert.Equal(t, uint64(10*32*2)+payloadSize, estimated)
+
+	var invalidLeaves *merkletree.Leaves
+	estimated, err = invalidLeaves.EstimateMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Zero(t, estimated)
+}
+
+// Build tree with memory budget
+func TestLeaves_BuildTree_WithMemoryBudget(t *testing.T) {
+	leaves := MockLeaves.Clone()
+
+	// Build tree within budget
+	_, _, err := leaves.BuildTree(merkletree.WithMemoryBudget(1 << 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Build tree over budget
+	_, _, err = MockLeaves.Clone().BuildTree(merkletree.WithMemoryBudget(64))
+	if err != nil {
+		t.Log("build tree failed as expected, err=", err)
+	}
+
+	var budgetErr *merkletree.BudgetError
+	assert.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, uint64(64), budgetErr.Budget)
+	assert.Greater(t, budgetErr.Estimated, budgetErr.Budget)
+}
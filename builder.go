@@ -0,0 +1,200 @@
+package merkletree
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Builder computes the root of a merkle tree incrementally. Only the
+// roots of completed subtrees are kept, at most one per level, so memory is
+// O(log n) regardless of leaf count. The root is the same as the one built
+// by BuildTree with the same options.
+type Builder struct {
+	opts   Options
+	count  uint64
+	levels []Hash
+
+	// digest size of hash function, zero until the first leaf
+	size int
+}
+
+// NewBuilder returns a new builder
+func NewBuilder(opt ...OptionFunc) *Builder {
+	return &Builder{
+		opts: NewOptions(opt...),
+	}
+}
+
+// Count returns number of leaves added
+func (b *Builder) Count() uint64 {
+	return b.count
+}
+
+// Add hash the payload & add it as a leaf
+func (b *Builder) Add(payload []byte) error {
+	digest, err := hashLeaf(b.opts.HashFunc, b.opts.LeafTag, payload)
+	if err != nil {
+		return err
+	}
+
+	return b.AddHash(digest)
+}
+
+// AddHash add a leaf by hash, returns *SizeError if hash is not of the
+// digest size of hash function
+func (b *Builder) AddHash(hash Hash) error {
+	if err := b.opts.Context.Err(); err != nil {
+		return err
+	} else if err := b.opts.checkBinary(); err != nil {
+		return err
+	} else if err := b.opts.checkOddRule(); err != nil {
+		return err
+	} else if err := b.checkDigest(hash); err != nil {
+		return err
+	}
+
+	b.count++
+
+	return b.push(0, hash)
+}
+
+// checkDigest returns *SizeError if hash is not of the digest size of hash
+// function
+func (b *Builder) checkDigest(hash Hash) error {
+	if b.size == 0 {
+		size, err := b.opts.digestSize()
+		if err != nil {
+			return err
+		}
+		b.size = size
+	}
+
+	return checkDigest(fmt.Sprintf("leaf %d", b.count), hash, b.size)
+}
+
+// AddLeaves add a batch of leaves, leaves are hashed unless SkipHash is
+// set. The synthetic duplicate appended by BuildTree is skipped. The hash of
+// a streamed payload is stored in its leaf, as the reader can only be read
+// once. The batch is not retained by the builder.
+func (b *Builder) AddLeaves(leaves *Leaves) error {
+	for i := 0; i < leaves.Length(); i++ {
+		leaf := &(*leaves)[i]
+		if leaf.Synthetic {
+			continue
+		}
+
+		hash := leaf.Hash
+		if !b.opts.SkipHash && !(leaf.consumed() && leaf.Hash != nil) {
+			var err error
+			if hash, err = leaf.hashPayload(b.opts.HashFunc, b.opts.LeafTag); err != nil {
+				return err
+			}
+			if leaf.stream != nil {
+				leaf.Hash = hash
+			}
+		}
+
+		if err := b.AddHash(hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Root returns root hash of the leaves added so far, the builder can still
+// be used after. The empty hash is returned without leaves if AllowEmpty
+// is set
+func (b *Builder) Root() (Hash, error) {
+	if b.count == 0 {
+		if !b.opts.AllowEmpty {
+			return nil, errors.New("not found leaf")
+		}
+		return b.opts.emptyHash()
+	} else if b.count == 1 && b.opts.SingleLeafRoot {
+		return b.levels[0], nil
+	} else if b.opts.OddRule == OddPromote {
+		return b.promotedRoot()
+	}
+
+	var carry Hash
+	for y := 0; y < len(b.levels); y++ {
+		pending := b.levels[y]
+		above := b.pendingAbove(y)
+
+		var err error
+		switch {
+		case pending != nil && carry != nil:
+			carry, err = hashPair(b.opts.HashFunc, b.opts.NodeTag, pending, carry, b.opts.SortedPairHashing)
+		case pending != nil:
+			// A single leaf is paired with its duplicate
+			if y > 0 && !above {
+				return pending, nil
+			}
+			carry, err = hashPair(b.opts.HashFunc, b.opts.NodeTag, pending, pending, b.opts.SortedPairHashing)
+		case carry != nil:
+			if !above {
+				return carry, nil
+			}
+			carry, err = hashPair(b.opts.HashFunc, b.opts.NodeTag, carry, carry, b.opts.SortedPairHashing)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return carry, nil
+}
+
+// promotedRoot returns root hash of the leaves added so far, the pending
+// node of a level without brother is promoted to the level above
+func (b *Builder) promotedRoot() (Hash, error) {
+	var carry Hash
+	for _, pending := range b.levels {
+		switch {
+		case pending != nil && carry != nil:
+			var err error
+			if carry, err = hashPair(b.opts.HashFunc, b.opts.NodeTag, pending, carry, b.opts.SortedPairHashing); err != nil {
+				return nil, err
+			}
+		case pending != nil:
+			carry = pending
+		}
+	}
+
+	return carry, nil
+}
+
+// push add node to level y, merge completed pairs upward
+func (b *Builder) push(y int, hash Hash) error {
+	for {
+		if y == len(b.levels) {
+			b.levels = append(b.levels, nil)
+		}
+
+		if b.levels[y] == nil {
+			b.levels[y] = hash
+			return nil
+		}
+
+		digest, err := hashPair(b.opts.HashFunc, b.opts.NodeTag, b.levels[y], hash, b.opts.SortedPairHashing)
+		if err != nil {
+			return err
+		}
+
+		b.levels[y] = nil
+		hash = digest
+		y++
+	}
+}
+
+// pendingAbove returns true if any level above y has a pending node
+func (b *Builder) pendingAbove(y int) bool {
+	for i := y + 1; i < len(b.levels); i++ {
+		if b.levels[i] != nil {
+			return true
+		}
+	}
+
+	return false
+}
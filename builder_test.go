@@ -0,0 +1,105 @@
+package merkletree_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLeaves returns n leaves
+func mockLeaves(n int) *merkletree.Leaves {
+	leaves := make(merkletree.Leaves, 0, n)
+	for i := 0; i < n; i++ {
+		leaves.Add(&merkletree.Leaf{Payload: []byte{byte(i), byte(i >> 8)}})
+	}
+
+	return &leaves
+}
+
+// Build root with builder, compare with BuildTree
+func TestBuilder_Root(t *testing.T) {
+	for _, sorted := range []bool{false, true} {
+		for size := 1; size <= 33; size++ {
+			opts := []merkletree.OptionFunc{
+				merkletree.WithHashFunc(GetCustomHashFunc()),
+				merkletree.WithSortedPairHashing(sorted),
+			}
+
+			_, root, err := mockLeaves(size).BuildTree(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			builder := merkletree.NewBuilder(opts...)
+			for _, leaf := range *mockLeaves(size) {
+				if err := builder.Add(leaf.Payload); err != nil {
+					t.Fatal(err)
+				}
+			}
+			assert.Equal(t, uint64(size), builder.Count())
+
+			rootHash, err := builder.Root()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, root.Hash, rootHash, "size=%d sorted=%v", size, sorted)
+		}
+	}
+}
+
+// Build root with builder by batches
+func TestBuilder_AddLeaves(t *testing.T) {
+	built := mockLeaves(100)
+	_, root, err := built.BuildTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := merkletree.NewBuilder()
+	leaves := *mockLeaves(100)
+	for i := 0; i < len(leaves); i += 16 {
+		end := i + 16
+		if end > len(leaves) {
+			end = len(leaves)
+		}
+		batch := leaves[i:end]
+		if err := builder.AddLeaves(&batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootHash, err := builder.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rootHash)
+
+	// Test skip hash, the leaves are hashed already
+	builder = merkletree.NewBuilder(merkletree.WithSkipHash(true))
+	if err := builder.AddLeaves(built); err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err = builder.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rootHash)
+}
+
+// Build root with invalid builder
+func TestBuilder_Invalid(t *testing.T) {
+	// Test empty builder
+	_, err := merkletree.NewBuilder().Root()
+	if err != nil {
+		t.Log("builder is empty, get root failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = merkletree.NewBuilder(merkletree.WithContext(ctx)).Add([]byte("Hello"))
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,130 @@
+package merkletree
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+)
+
+// Names of built-in compressors
+const (
+	// CompressionGzip is gzip of default level
+	CompressionGzip = "gzip"
+)
+
+// Compressor compresses data of versioned envelopes
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// LimitedDecompressor compressor which stops decompressing beyond a limit,
+// which is optional for Compressor to reject decompression bombs early
+type LimitedDecompressor interface {
+	DecompressLimit(data []byte, limit int) ([]byte, error)
+}
+
+var (
+	// compressors registered compressors by name
+	compressors   = make(map[string]Compressor)
+	compressorsMu sync.RWMutex
+)
+
+func init() {
+	RegisterCompressor(CompressionGzip, GzipCompressor{Level: gzip.DefaultCompression})
+}
+
+// RegisterCompressor register compressor by name, which is flagged in the
+// envelope header. e.g. register a zstd compressor as "zstd".
+func RegisterCompressor(name string, c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+
+	compressors[name] = c
+}
+
+// GetCompressor returns compressor registered by name
+func GetCompressor(name string) (Compressor, error) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+
+	c, ok := compressors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown compressor '%s'", name)
+	}
+
+	return c, nil
+}
+
+// CompressorNames returns names of registered compressors in sorted order
+func CompressorNames() []string {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+
+	names := make([]string, 0, len(compressors))
+	for name := range compressors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GzipCompressor gzip compressor
+type GzipCompressor struct {
+	// Level of compression
+	Level int
+}
+
+// Compress returns gzip of data
+func (c GzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, c.Level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress returns data of gzip
+func (c GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+// DecompressLimit returns data of gzip, returns LimitError if data exceeds
+// limit bytes
+func (c GzipCompressor) DecompressLimit(data []byte, limit int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// Read one more byte to find out data exceeds limit
+	decompressed, err := ioutil.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	} else if len(decompressed) > limit {
+		return nil, &LimitError{Name: "decompressed size", Limit: uint64(limit), Actual: uint64(len(decompressed))}
+	}
+
+	return decompressed, nil
+}
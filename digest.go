@@ -0,0 +1,91 @@
+package merkletree
+
+import (
+	"fmt"
+)
+
+// digestSize returns digest size of the configured hash function
+func (opts Options) digestSize() (int, error) {
+	return hashSize(opts.HashFunc)
+}
+
+// checkDigest returns *SizeError if size of hash is not size
+func checkDigest(name string, hash Hash, size int) error {
+	if len(hash) != size {
+		return &SizeError{Name: "hash size of " + name, Expected: uint64(size), Actual: uint64(len(hash))}
+	}
+
+	return nil
+}
+
+// checkDigests returns *SizeError if hash of any leaf is not size
+func (obj *Leaves) checkDigests(size int) error {
+	for i := 0; i < obj.Length(); i++ {
+		if err := checkDigest(fmt.Sprintf("leaf %d", i), (*obj)[i].Hash, size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkDigests returns *SizeError if any hash of tree is not size
+func (tree *Tree) checkDigests(size int) error {
+	for y, level := range *tree {
+		for x, hash := range level {
+			if err := checkDigest(fmt.Sprintf("node (%d,%d)", y, x), hash, size); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkDigests returns *SizeError if hash of any node of graph is not size,
+// shared subtrees are checked once
+func (node *Node) checkDigests(size int) error {
+	visited := make(map[*Node]bool)
+	stack := []*Node{node}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == nil || visited[n] {
+			continue
+		}
+		visited[n] = true
+
+		if err := checkDigest(fmt.Sprintf("node of height %d", n.Height), n.Hash, size); err != nil {
+			return err
+		}
+		stack = append(stack, n.children()...)
+	}
+
+	return nil
+}
+
+// checkDigests returns *SizeError if leaf or any sibling of proof is not size
+func (proof *Proof) checkDigests(size int) error {
+	if err := checkDigest("leaf", proof.Leaf, size); err != nil {
+		return err
+	}
+
+	for i, sibling := range proof.Siblings {
+		if err := checkDigest(fmt.Sprintf("sibling %d", i), sibling, size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkDigests returns *SizeError if any hash of artifact is not of the
+// digest size of the configured hash function
+func (opts Options) checkDigests(artifact interface{ checkDigests(size int) error }) error {
+	size, err := opts.digestSize()
+	if err != nil {
+		return err
+	}
+
+	return artifact.checkDigests(size)
+}
@@ -0,0 +1,180 @@
+package merkletree_test
+
+import (
+	"crypto/sha1"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build tree from leaf hashes of mixed sizes
+func TestLeaves_BuildTree_DigestSize(t *testing.T) {
+	h := GetCustomHashFunc()
+	short := &merkletree.HashFunc{Provider: sha1.New}
+
+	leaves := mockLeaves(4)
+	if err := leaves.Hash(h); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := short.Hash([]byte("Hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	(*leaves)[2].Hash = digest
+
+	_, _, err = leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithSkipHash(true))
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	t.Log("digest size mismatched, build tree failed as expected, err=", err)
+
+	// Test builder
+	builder := merkletree.NewBuilder(merkletree.WithHashFunc(h))
+	assert.Nil(t, builder.AddHash(goodHash))
+	err = builder.AddHash(digest)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	assert.Equal(t, uint64(1), builder.Count())
+
+	err = merkletree.NewBuilder(merkletree.WithHashFunc(h), merkletree.WithSkipHash(true)).AddLeaves(leaves)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+
+	// Test window
+	w, err := merkletree.NewWindow(4, merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.AppendHash(digest)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	assert.Equal(t, 0, w.Len())
+
+	// Leaves of the hash function's size are fine
+	(*leaves)[2].Hash = goodHash
+	_, _, err = leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithSkipHash(true))
+	assert.Nil(t, err)
+}
+
+// Unmarshal tree, root & proof built by a hash function of another size
+func TestUnmarshal_DigestSize(t *testing.T) {
+	short := &merkletree.HashFunc{Provider: sha1.New}
+	opt := merkletree.WithHashFunc(short)
+
+	tree, root, err := mockLeaves(5).BuildTree(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := tree.GetProof(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	treeBytes, err := tree.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootBytes, err := root.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofBytes, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test default hash function
+	_, err = merkletree.UnmarshalTree(treeBytes)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	t.Log("digest size mismatched, unmarshal tree failed as expected, err=", err)
+
+	_, err = merkletree.UnmarshalRoot(rootBytes)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+
+	_, err = merkletree.UnmarshalProof(proofBytes)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+
+	// Test the hash function building them
+	tree2, err := merkletree.UnmarshalTree(treeBytes, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *tree, *tree2)
+
+	root2, err := merkletree.UnmarshalRoot(rootBytes, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, root2.Hash)
+
+	proof2, err := merkletree.UnmarshalProof(proofBytes, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := proof2.Verify(root.Hash, short)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Test proof token of another hash function
+	token := &merkletree.ProofToken{Hash: merkletree.HashSHA256, Root: root.Hash, Proof: proof}
+	_, err = token.Verify()
+	assert.IsType(t, &merkletree.SizeError{}, err)
+}
+
+// Update, build empty tree & verify proofs with hashes of another size
+func TestDigestSize_Others(t *testing.T) {
+	h := GetCustomHashFunc()
+	digest, err := (&merkletree.HashFunc{Provider: sha1.New}).Hash([]byte("Hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, root, err := mockLeaves(5).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test update
+	_, err = root.Update(1, merkletree.Leaf{Hash: digest}, h)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	t.Log("digest size mismatched, update failed as expected, err=", err)
+
+	// Test empty hash
+	var empty merkletree.Leaves
+	_, _, err = empty.BuildTree(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true), merkletree.WithEmptyHash(digest))
+	assert.IsType(t, &merkletree.SizeError{}, err)
+
+	_, err = merkletree.NewBuilder(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true), merkletree.WithEmptyHash(digest)).Root()
+	assert.IsType(t, &merkletree.SizeError{}, err)
+
+	// Test proof decoded without hash function
+	proof, err := tree.GetProof(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Leaf = digest
+	for i := range proof.Siblings {
+		proof.Siblings[i] = digest
+	}
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded merkletree.Proof
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	_, err = decoded.Verify(root.Hash, h)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+
+	// Test k-ary proof
+	kary, err := tree.GetKaryProof(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kary.Groups[1] = []merkletree.Hash{digest}
+	_, err = kary.Verify(root.Hash, h)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+
+	kary.Leaf = digest
+	_, err = kary.Verify(root.Hash, h)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+}
@@ -0,0 +1,122 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build tree without leaves
+func TestLeaves_BuildTree_AllowEmpty(t *testing.T) {
+	h := GetCustomHashFunc()
+	emptyHash, err := h.Hash(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Default empty hash
+	var leaves merkletree.Leaves
+	tree, root, err := leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("EmptyRoot(hex)=", merkletree.Hex(root.Hash))
+	assert.Equal(t, emptyHash, root.Hash)
+	assert.Equal(t, 0, root.Height)
+	assert.Equal(t, uint64(0), tree.Height())
+
+	_, err = tree.PathForLeaf(0)
+	assert.IsType(t, &merkletree.IndexError{}, err)
+	t.Log("tree is empty, get path failed as expected")
+
+	// The tree reports the root with the same options
+	rootHash, err := tree.GetRootHash(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rootHash)
+	_, err = tree.GetRootHash()
+	assert.NotNil(t, err)
+
+	// Marshaled tree has no levels, the root is derived from options
+	data, err := tree.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree2, err := merkletree.UnmarshalTree(data, merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(0), tree2.Height())
+	rootHash, err = tree2.GetRootHash(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rootHash)
+
+	// Tree of fixed-size hashes
+	tree32, err := leaves.BuildTree32(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash32, err := tree32.GetRootHash(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rootHash32[:])
+	_, err = leaves.BuildTree32(merkletree.WithHashFunc(h))
+	assert.NotNil(t, err)
+
+	// Configured empty hash
+	_, root, err = (*merkletree.Leaves)(nil).BuildTree(merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true), merkletree.WithEmptyHash(goodHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, goodHash, root.Hash)
+
+	// Empty hash is ignored without AllowEmpty
+	_, _, err = leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithEmptyHash(goodHash))
+	assert.NotNil(t, err)
+	t.Log("empty is not allowed, build tree failed as expected")
+}
+
+// Root of builder & window without leaves
+func TestBuilder_Root_AllowEmpty(t *testing.T) {
+	h := GetCustomHashFunc()
+	emptyHash, err := h.Hash(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []merkletree.OptionFunc{merkletree.WithHashFunc(h), merkletree.WithAllowEmpty(true)}
+
+	root, err := merkletree.NewBuilder(opts...).Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, emptyHash, root)
+
+	w, err := merkletree.NewWindow(4, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err = w.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, emptyHash, root)
+
+	// Builder grows from size 0
+	b := merkletree.NewBuilder(opts...)
+	if err = b.Add([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	root, err = b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, emptyHash, root)
+
+	_, err = merkletree.NewBuilder(merkletree.WithHashFunc(h)).Root()
+	assert.NotNil(t, err)
+}
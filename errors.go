@@ -0,0 +1,112 @@
+package merkletree
+
+import (
+	"fmt"
+)
+
+// PositionError is returned when a position (y, x) is out of the tree
+type PositionError struct {
+	// Y & X of the position
+	Y uint64
+	X uint64
+
+	// Height of the tree
+	Height uint64
+
+	// Width of level y, zero if y is out of the tree
+	Width uint64
+}
+
+// Error returns error message
+func (e *PositionError) Error() string {
+	if e.Y >= e.Height {
+		return fmt.Sprintf("invalid y: position (%d,%d) is out of tree of height %d", e.Y, e.X, e.Height)
+	}
+
+	return fmt.Sprintf("invalid x: position (%d,%d) is out of level %d of width %d", e.Y, e.X, e.Y, e.Width)
+}
+
+// IndexError is returned when a leaf index is out of range
+type IndexError struct {
+	// Index of the leaf
+	Index uint64
+
+	// Count is the number of leaves
+	Count uint64
+}
+
+// Error returns error message
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("invalid index %d, number of leaves is %d", e.Index, e.Count)
+}
+
+// SizeError is returned when a size mismatches the expected one
+type SizeError struct {
+	// Name of the size
+	Name string
+
+	// Expected size
+	Expected uint64
+
+	// Actual size
+	Actual uint64
+}
+
+// Error returns error message
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("invalid %s %d, expected %d", e.Name, e.Actual, e.Expected)
+}
+
+// PathError is returned when a position of a merkle path is invalid
+type PathError struct {
+	// Step is the index of the position in the path
+	Step int
+
+	// PoN is the position
+	PoN PoN
+
+	// Err is the cause
+	Err error
+}
+
+// Error returns error message
+func (e *PathError) Error() string {
+	return fmt.Sprintf("invalid position %d (%d,%d) of path: %v", e.Step, e.PoN[0], e.PoN[1], e.Err)
+}
+
+// Unwrap returns the cause
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// positionError returns *PositionError of (y, x) in tree
+func (tree *Tree) positionError(y uint64, x uint64) *PositionError {
+	e := &PositionError{
+		Y:      y,
+		X:      x,
+		Height: tree.Height(),
+	}
+	if y < e.Height {
+		e.Width = tree.Width(y)
+	}
+
+	return e
+}
+
+// LimitError is returned when decoded input exceeds a limit of DecodeLimits
+type LimitError struct {
+	// Name of the limit
+	Name string
+
+	// Limit configured
+	Limit uint64
+
+	// Actual size, which is at least the size found when decoding is
+	// stopped early
+	Actual uint64
+}
+
+// Error returns error message
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %d exceeds limit %d", e.Name, e.Actual, e.Limit)
+}
@@ -0,0 +1,62 @@
+package merkletree_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Errors carry coordinates & indexes
+func TestErrors(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test position error of GetHash
+	_, err = tree.GetHash(1, 5)
+	t.Log("Err=", err)
+	var positionErr *merkletree.PositionError
+	assert.True(t, errors.As(err, &positionErr))
+	assert.Equal(t, merkletree.PositionError{Y: 1, X: 5, Height: 5, Width: 5}, *positionErr)
+
+	_, err = tree.GetHash(5, 0)
+	t.Log("Err=", err)
+	assert.True(t, errors.As(err, &positionErr))
+	assert.Equal(t, merkletree.PositionError{Y: 5, X: 0, Height: 5}, *positionErr)
+
+	// Test path error of Prove, wraps position error
+	merklePath := merkletree.PoNs{{0, 1}, {1, 7}}
+	_, err = tree.Prove(&merklePath, goodHash, h)
+	t.Log("Err=", err)
+	var pathErr *merkletree.PathError
+	assert.True(t, errors.As(err, &pathErr))
+	assert.Equal(t, 1, pathErr.Step)
+	assert.Equal(t, merkletree.PoN{1, 7}, pathErr.PoN)
+	assert.True(t, errors.As(err, &positionErr))
+
+	// Test path error of Validate
+	err = merklePath.Validate(tree)
+	t.Log("Err=", err)
+	assert.True(t, errors.As(err, &pathErr))
+	assert.Equal(t, 1, pathErr.Step)
+
+	// Test index error
+	_, err = tree.PathForLeaf(10)
+	t.Log("Err=", err)
+	var indexErr *merkletree.IndexError
+	assert.True(t, errors.As(err, &indexErr))
+	assert.Equal(t, merkletree.IndexError{Index: 10, Count: 10}, *indexErr)
+
+	// Test size error
+	(*tree)[2] = (*tree)[2][:1]
+	_, err = tree.Verify(h)
+	t.Log("Err=", err)
+	var sizeErr *merkletree.SizeError
+	assert.True(t, errors.As(err, &sizeErr))
+	assert.Equal(t, uint64(3), sizeErr.Expected)
+	assert.Equal(t, uint64(1), sizeErr.Actual)
+}
@@ -0,0 +1,386 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+)
+
+// Hash32Size size of Hash32 in bytes
+const Hash32Size = 32
+
+// Hash32 fixed-size node hash, for 256-bit hash functions
+type Hash32 [Hash32Size]byte
+
+// Tree32 merkle tree of fixed-size hashes, the layout is the same as Tree
+type Tree32 [][]Hash32
+
+// IHash32Func hash interface returning fixed-size digest, which is optional
+// for IHashFunc to avoid allocating the digest
+type IHash32Func interface {
+	Hash32(msg []byte) (Hash32, error)
+}
+
+// Hash32 returns message digest, which must be 32 bytes. A hash state is
+// created per call, BuildTree32 & Proof32.Verify reuse one for all pairs.
+func (h *HashFunc) Hash32(msg []byte) (Hash32, error) {
+	var digest Hash32
+
+	provider := h.Provider()
+	if provider.Size() != Hash32Size {
+		return digest, &SizeError{Name: "hash size", Expected: Hash32Size, Actual: uint64(provider.Size())}
+	}
+
+	if _, err := provider.Write(msg); err != nil {
+		return digest, err
+	}
+	provider.Sum(digest[:0])
+
+	return digest, nil
+}
+
+// hash32 returns digest of msg by h, the digest must be 32 bytes
+func hash32(h IHashFunc, msg []byte) (Hash32, error) {
+	if h32, ok := h.(IHash32Func); ok {
+		return h32.Hash32(msg)
+	}
+
+	var digest Hash32
+
+	hash, err := h.Hash(msg)
+	if err != nil {
+		return digest, err
+	} else if len(hash) != Hash32Size {
+		return digest, &SizeError{Name: "hash size", Expected: Hash32Size, Actual: uint64(len(hash))}
+	}
+	copy(digest[:], hash)
+
+	return digest, nil
+}
+
+// hasher32 hashes messages to Hash32 by one hash state reused for all of
+// them, if the hash function is a *HashFunc or a *PooledHashFunc. It's not
+// safe for concurrent use.
+type hasher32 struct {
+	h     IHashFunc
+	state hash.Hash
+	buf   []byte
+	pool  *PooledHashFunc
+}
+
+// newHasher32 returns hasher of h, which must be released after use
+func newHasher32(h IHashFunc) (*hasher32, error) {
+	hr := &hasher32{h: h}
+
+	switch f := h.(type) {
+	case *HashFunc:
+		hr.state = f.Provider()
+	case *PooledHashFunc:
+		hr.state = f.pool.Get().(hash.Hash)
+		hr.pool = f
+	default:
+		return hr, nil
+	}
+
+	if size := hr.state.Size(); size != Hash32Size {
+		hr.release()
+		return nil, &SizeError{Name: "hash size", Expected: Hash32Size, Actual: uint64(size)}
+	}
+	hr.buf = make([]byte, 0, Hash32Size)
+
+	return hr, nil
+}
+
+// hash returns digest of msg
+func (hr *hasher32) hash(msg []byte) (Hash32, error) {
+	if hr.state == nil {
+		return hash32(hr.h, msg)
+	}
+
+	var digest Hash32
+
+	hr.state.Reset()
+	if _, err := hr.state.Write(msg); err != nil {
+		return digest, err
+	}
+	copy(digest[:], hr.state.Sum(hr.buf[:0]))
+
+	return digest, nil
+}
+
+// release returns the hash state to its pool
+func (hr *hasher32) release() {
+	if hr.pool != nil && hr.state != nil {
+		hr.pool.pool.Put(hr.state)
+	}
+	hr.state = nil
+}
+
+// BuildTree32 build tree of fixed-size hashes by options, the root is the
+// same as the one built by BuildTree. Only OddDuplicate is supported.
+// Without leaves, a tree of no levels is returned if AllowEmpty is set, like
+// BuildTree. Returns error if digest of hash function is not 32 bytes.
+func (obj *Leaves) BuildTree32(opt ...OptionFunc) (*Tree32, error) {
+	opts := NewOptions(opt...)
+	if err := opts.checkBinary(); err != nil {
+		return nil, err
+	} else if err := opts.checkDuplicate(); err != nil {
+		return nil, err
+	}
+
+	if obj == nil || obj.IsEmpty() {
+		if !opts.AllowEmpty {
+			return nil, errors.New("not found leaf")
+		}
+		return &Tree32{}, nil
+	}
+
+	if !opts.SkipHash {
+		if err := obj.hash(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if obj.Length()%2 == 1 && !(obj.Length() == 1 && opts.SingleLeafRoot) {
+		clone := obj.LastLeaf().Clone()
+		clone.Synthetic = true
+		*obj = append(*obj, *clone)
+	}
+
+	level := make([]Hash32, obj.Length())
+	for i, leaf := range *obj {
+		if len(leaf.Hash) != Hash32Size {
+			return nil, &SizeError{Name: "hash size", Expected: Hash32Size, Actual: uint64(len(leaf.Hash))}
+		}
+		copy(level[i][:], leaf.Hash)
+	}
+
+	tree := Tree32{level}
+
+	// Message buffer & hash state are reused by all pairs
+	tag := len(opts.NodeTag)
+	msg := make([]byte, tag+2*Hash32Size)
+	copy(msg, opts.NodeTag)
+	hr, err := newHasher32(opts.HashFunc)
+	if err != nil {
+		return nil, err
+	}
+	defer hr.release()
+
+	for len(level) > 1 {
+		if err := opts.Context.Err(); err != nil {
+			return nil, err
+		}
+
+		branches := make([]Hash32, (len(level)+1)/2)
+		for i := range branches {
+			left, right := level[2*i], level[2*i]
+			if 2*i+1 < len(level) {
+				right = level[2*i+1]
+			}
+			if opts.SortedPairHashing && bytes.Compare(left[:], right[:]) > 0 {
+				left, right = right, left
+			}
+
+			copy(msg[tag:tag+Hash32Size], left[:])
+			copy(msg[tag+Hash32Size:], right[:])
+
+			digest, err := hr.hash(msg)
+			if err != nil {
+				return nil, err
+			}
+			branches[i] = digest
+		}
+
+		tree = append(tree, branches)
+		level = branches
+	}
+
+	return &tree, nil
+}
+
+// Height returns height of tree
+func (tree *Tree32) Height() uint64 {
+	if tree == nil {
+		return 0
+	}
+	return uint64(len(*tree))
+}
+
+// GetRootHash returns root hash, the empty hash is returned for a tree of no
+// levels if AllowEmpty is set like Tree.GetRootHash
+func (tree *Tree32) GetRootHash(opt ...OptionFunc) (Hash32, error) {
+	var digest Hash32
+
+	if tree == nil || tree.Height() == 0 {
+		opts := NewOptions(opt...)
+		if !opts.AllowEmpty {
+			return digest, errors.New("tree is empty")
+		}
+
+		hash, err := opts.emptyHash()
+		if err != nil {
+			return digest, err
+		} else if len(hash) != Hash32Size {
+			return digest, &SizeError{Name: "hash size", Expected: Hash32Size, Actual: uint64(len(hash))}
+		}
+		copy(digest[:], hash)
+
+		return digest, nil
+	}
+
+	return (*tree)[tree.Height()-1][0], nil
+}
+
+// GetHash returns hash by (y,x)
+func (tree *Tree32) GetHash(y uint64, x uint64) (Hash32, error) {
+	if tree == nil || tree.Height() == 0 {
+		return Hash32{}, errors.New("tree is empty")
+	} else if y >= tree.Height() || x >= uint64(len((*tree)[y])) {
+		e := &PositionError{Y: y, X: x, Height: tree.Height()}
+		if y < e.Height {
+			e.Width = uint64(len((*tree)[y]))
+		}
+		return Hash32{}, e
+	}
+
+	return (*tree)[y][x], nil
+}
+
+// GetProof returns proof of the leaf at index, the synthetic duplicate is
+// rejected if the leaf count is configured by WithLeafCount
+func (tree *Tree32) GetProof(index uint64, opt ...OptionFunc) (*Proof32, error) {
+	if tree == nil || tree.Height() == 0 {
+		return nil, errors.New("tree is empty")
+	}
+
+	opts := NewOptions(opt...)
+	count, err := opts.leafCount(uint64(len((*tree)[0])))
+	if err != nil {
+		return nil, err
+	} else if index >= count {
+		return nil, &IndexError{Index: index, Count: count}
+	}
+
+	proof := &Proof32{
+		Index:    index,
+		Leaf:     (*tree)[0][index],
+		Path:     make(PoNs, 0, tree.Height()-1),
+		Siblings: make([]Hash32, 0, tree.Height()-1),
+	}
+
+	x := index
+	for y := uint64(0); y < tree.Height()-1; y++ {
+		brother := x ^ 1
+		if brother >= uint64(len((*tree)[y])) {
+			brother = x
+		}
+		proof.Path = append(proof.Path, PoN{y, brother})
+		proof.Siblings = append(proof.Siblings, (*tree)[y][brother])
+		x /= 2
+	}
+
+	return proof, nil
+}
+
+// Tree returns tree of variable-size hashes
+func (tree *Tree32) Tree() *Tree {
+	if tree == nil {
+		return nil
+	}
+
+	t := make(Tree, len(*tree))
+	for y, level := range *tree {
+		t[y] = make([]Hash, len(level))
+		for x := range level {
+			t[y][x] = append(Hash{}, level[x][:]...)
+		}
+	}
+
+	return &t
+}
+
+// Proof32 is a self-contained merkle proof of fixed-size hashes
+type Proof32 struct {
+	// Index of the leaf
+	Index uint64
+
+	// Leaf hash
+	Leaf Hash32
+
+	// Path is the merkle path in coordinates
+	Path PoNs
+
+	// Siblings are the hashes referred by path, from leaf to the root
+	Siblings []Hash32
+}
+
+// Verify returns true if the proof leads to root, the index is checked like
+// Proof.Verify
+func (proof *Proof32) Verify(root Hash32, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	if proof == nil {
+		return false, errors.New("proof is empty")
+	} else if len(proof.Path) != len(proof.Siblings) {
+		return false, &SizeError{Name: "number of siblings", Expected: uint64(len(proof.Path)), Actual: uint64(len(proof.Siblings))}
+	} else if err := checkIndex(proof.Index, proof.Path, false); err != nil {
+		return false, err
+	}
+	opts := NewOptions(opt...)
+	if err := opts.checkDuplicate(); err != nil {
+		return false, err
+	}
+
+	tag := len(opts.NodeTag)
+	msg := make([]byte, tag+2*Hash32Size)
+	copy(msg, opts.NodeTag)
+	hr, err := newHasher32(h)
+	if err != nil {
+		return false, err
+	}
+	defer hr.release()
+
+	digest := proof.Leaf
+
+	for i, pon := range proof.Path {
+		x := proof.Index >> pon[0]
+		if pon[1] == x && proof.Siblings[i] != digest {
+			return false, &PathError{Step: i, PoN: pon, Err: errors.New("node paired with itself has another sibling")}
+		}
+
+		left, right := digest, proof.Siblings[i]
+		if x%2 == 1 {
+			left, right = right, left
+		}
+		if opts.SortedPairHashing && bytes.Compare(left[:], right[:]) > 0 {
+			left, right = right, left
+		}
+
+		copy(msg[tag:tag+Hash32Size], left[:])
+		copy(msg[tag+Hash32Size:], right[:])
+
+		if digest, err = hr.hash(msg); err != nil {
+			return false, err
+		}
+	}
+
+	return digest == root, nil
+}
+
+// Proof returns proof of variable-size hashes
+func (proof *Proof32) Proof() *Proof {
+	if proof == nil {
+		return nil
+	}
+
+	p := &Proof{
+		Index:    proof.Index,
+		Leaf:     append(Hash{}, proof.Leaf[:]...),
+		Path:     append(PoNs{}, proof.Path...),
+		Siblings: make([]Hash, len(proof.Siblings)),
+	}
+	for i := range proof.Siblings {
+		p.Siblings[i] = append(Hash{}, proof.Siblings[i][:]...)
+	}
+
+	return p
+}
@@ -0,0 +1,148 @@
+package merkletree_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build tree of fixed-size hashes
+func TestLeaves_BuildTree32(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, n := range []int{1, 2, 3, 7, 100} {
+		for _, sorted := range []bool{false, true} {
+			opt := merkletree.WithSortedPairHashing(sorted)
+			tree, root, err := mockLeaves(n).BuildTree(merkletree.WithHashFunc(h), opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tree32, err := mockLeaves(n).BuildTree32(merkletree.WithHashFunc(h), opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tree, tree32.Tree())
+
+			rootHash, err := tree32.GetRootHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, root.Hash, rootHash[:])
+
+			for i := uint64(0); i < uint64(n); i++ {
+				proof32, err := tree32.GetProof(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				result, err := proof32.Verify(rootHash, h, opt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.True(t, result)
+
+				proof, err := tree.GetProof(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, proof, proof32.Proof())
+
+				// Test tampered index
+				proof32.Index ^= 1
+				_, err = proof32.Verify(rootHash, h, opt)
+				assert.NotNil(t, err)
+			}
+		}
+	}
+
+	// Test hash function of other size
+	_, err := mockLeaves(3).BuildTree32(merkletree.WithHashFunc(&merkletree.HashFunc{Provider: sha512.New}))
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	t.Log("hash size is not 32 bytes, build failed as expected, err=", err)
+}
+
+// Get hash & proof from tree of fixed-size hashes
+func TestTree32_GetHash(t *testing.T) {
+	tree32, err := MockLeaves.Clone().BuildTree32(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := tree32.GetHash(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, goodHash, hash[:])
+
+	_, err = tree32.GetHash(0, 100)
+	assert.IsType(t, &merkletree.PositionError{}, err)
+
+	_, err = tree32.GetProof(100)
+	assert.IsType(t, &merkletree.IndexError{}, err)
+
+	proof, err := tree32.GetProof(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, _ := tree32.GetRootHash()
+	rootHash[0] ^= 0xff
+	result, err := proof.Verify(rootHash, GetCustomHashFunc())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, result)
+	t.Log("bad root, verify failed as expected")
+
+	var empty *merkletree.Tree32
+	_, err = empty.GetRootHash()
+	assert.NotNil(t, err)
+}
+
+// Reuse one hash state for all pairs
+func TestTree32_Allocs(t *testing.T) {
+	h := &merkletree.HashFunc{Provider: sha256.New}
+	opt := merkletree.WithHashFunc(h)
+
+	allocs := make([]float64, 0, 2)
+	for _, n := range []int{4, 1024} {
+		leaves := mockLeaves(n)
+		if err := leaves.Hash(h); err != nil {
+			t.Fatal(err)
+		}
+		tree32, err := leaves.BuildTree32(opt, merkletree.WithSkipHash(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rootHash, err := tree32.GetRootHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof32, err := tree32.GetProof(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		allocs = append(allocs, testing.AllocsPerRun(100, func() {
+			if _, err := proof32.Verify(rootHash, h); err != nil {
+				t.Fatal(err)
+			}
+		}))
+
+		// Allocations are per level, not per pair
+		built := testing.AllocsPerRun(10, func() {
+			if _, err := leaves.BuildTree32(opt, merkletree.WithSkipHash(true)); err != nil {
+				t.Fatal(err)
+			}
+		})
+		t.Logf("leaves=%d, build allocs=%v", n, built)
+		if n > 64 {
+			assert.Less(t, built, float64(64))
+		}
+	}
+
+	// Allocations of verifying don't grow with path length
+	assert.Equal(t, allocs[0], allocs[1])
+}
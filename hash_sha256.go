@@ -0,0 +1,17 @@
+//go:build merkletree_sha256
+// +build merkletree_sha256
+
+package merkletree
+
+import (
+	"crypto/sha256"
+)
+
+// newDefaultHashFunc returns built-in default hash interface, SHA-256 from
+// the standard library. Used by builds with tag 'merkletree_sha256', which
+// do not depend on golang.org/x/crypto.
+func newDefaultHashFunc() IHashFunc {
+	hashFunc := new(HashFunc)
+	hashFunc.Provider = sha256.New
+	return hashFunc
+}
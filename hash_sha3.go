@@ -0,0 +1,33 @@
+//go:build !merkletree_sha256
+// +build !merkletree_sha256
+
+package merkletree
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+// ProfileEthereum is the profile of Ethereum style merkle trees, e.g. of
+// merkletreejs with sortPairs: Keccak-256, children are hashed in
+// byte-sorted order so proofs can be verified by commutative on-chain
+// verifiers, the last node of a level of odd width is promoted, the root of
+// a single leaf is the leaf
+var ProfileEthereum = &Profile{
+	Name:              "ethereum",
+	HashFunc:          newDefaultHashFunc(),
+	SortedPairHashing: true,
+	SingleLeafRoot:    true,
+	OddRule:           OddPromote,
+}
+
+func init() {
+	RegisterHashFunc(HashKeccak256, newDefaultHashFunc())
+	RegisterProfile(ProfileEthereum)
+}
+
+// newDefaultHashFunc returns built-in default hash interface, Keccak-256
+func newDefaultHashFunc() IHashFunc {
+	hashFunc := new(HashFunc)
+	hashFunc.Provider = sha3.NewLegacyKeccak256
+	return hashFunc
+}
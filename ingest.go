@@ -0,0 +1,111 @@
+package merkletree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NDJSONSelectFunc returns payload of a NDJSON record, record is the JSON
+// line. Returning nil payload skips the record.
+type NDJSONSelectFunc func(record []byte) ([]byte, error)
+
+// CSVSelectFunc returns payload of a CSV record, the record is reused by
+// the next call. Returning nil payload skips the record, e.g. the header.
+type CSVSelectFunc func(record []string) ([]byte, error)
+
+// IngestNDJSON read NDJSON records from r & add their payloads as leaves,
+// returns number of leaves added. Blank lines are skipped, the line itself
+// is the payload if selectFn is nil.
+func (b *Builder) IngestNDJSON(r io.Reader, selectFn NDJSONSelectFunc) (int, error) {
+	br := bufio.NewReader(r)
+
+	added := 0
+	for line := 1; ; line++ {
+		record, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return added, err
+		}
+		eof := err == io.EOF
+
+		record = bytes.TrimSpace(record)
+		if len(record) > 0 {
+			if !json.Valid(record) {
+				return added, fmt.Errorf("line %d: invalid JSON", line)
+			}
+
+			payload := record
+			if selectFn != nil {
+				if payload, err = selectFn(record); err != nil {
+					return added, fmt.Errorf("line %d: %w", line, err)
+				}
+			}
+
+			if payload != nil {
+				if err := b.Add(payload); err != nil {
+					return added, err
+				}
+				added++
+			}
+		}
+
+		if eof {
+			return added, nil
+		}
+	}
+}
+
+// IngestCSV read CSV records from r & add their payloads as leaves, returns
+// number of leaves added. The record encoded as a CSV line without line
+// break is the payload if selectFn is nil.
+func (b *Builder) IngestCSV(r io.Reader, selectFn CSVSelectFunc) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	added := 0
+	for n := 1; ; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return added, nil
+		} else if err != nil {
+			return added, err
+		}
+
+		var payload []byte
+		if selectFn != nil {
+			payload, err = selectFn(record)
+		} else {
+			payload, err = encodeCSVRecord(record)
+		}
+		if err != nil {
+			return added, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		if payload != nil {
+			if err := b.Add(payload); err != nil {
+				return added, err
+			}
+			added++
+		}
+	}
+}
+
+// encodeCSVRecord returns record encoded as a CSV line without line break
+func encodeCSVRecord(record []string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
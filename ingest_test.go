@@ -0,0 +1,128 @@
+package merkletree_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// rootOf returns root hash of payloads built by builder
+func rootOf(t *testing.T, payloads ...string) merkletree.Hash {
+	b := merkletree.NewBuilder(merkletree.WithHashFunc(GetCustomHashFunc()))
+	for _, payload := range payloads {
+		if err := b.Add([]byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root, err := b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+// Ingest NDJSON records
+func TestBuilder_IngestNDJSON(t *testing.T) {
+	data := `{"id":1,"name":"alice"}
+{"id":2,"name":"bob"}
+
+{"id":3,"name":"carol"}`
+
+	// Whole lines
+	b := merkletree.NewBuilder(merkletree.WithHashFunc(GetCustomHashFunc()))
+	added, err := b.IngestNDJSON(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, added)
+	root, err := b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootOf(t, `{"id":1,"name":"alice"}`, `{"id":2,"name":"bob"}`, `{"id":3,"name":"carol"}`), root)
+
+	// Selected field, skipping bob
+	b = merkletree.NewBuilder(merkletree.WithHashFunc(GetCustomHashFunc()))
+	added, err = b.IngestNDJSON(strings.NewReader(data), func(record []byte) ([]byte, error) {
+		var v struct{ Name string }
+		if err := json.Unmarshal(record, &v); err != nil {
+			return nil, err
+		} else if v.Name == "bob" {
+			return nil, nil
+		}
+		return []byte(v.Name), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, added)
+	root, err = b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootOf(t, "alice", "carol"), root)
+
+	// Test invalid JSON
+	b = merkletree.NewBuilder(merkletree.WithHashFunc(GetCustomHashFunc()))
+	added, err = b.IngestNDJSON(strings.NewReader("{}\n{"), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, added)
+	t.Log("invalid JSON, ingest failed as expected, err=", err)
+
+	// Test error of select func
+	errSelect := errors.New("select failed")
+	_, err = b.IngestNDJSON(strings.NewReader("{}"), func(record []byte) ([]byte, error) {
+		return nil, errSelect
+	})
+	assert.True(t, errors.Is(err, errSelect))
+}
+
+// Ingest CSV records
+func TestBuilder_IngestCSV(t *testing.T) {
+	data := "id,name\n1,alice\n2,\"bob, jr\"\n"
+
+	// Whole records
+	b := merkletree.NewBuilder(merkletree.WithHashFunc(GetCustomHashFunc()))
+	added, err := b.IngestCSV(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, added)
+	root, err := b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootOf(t, "id,name", "1,alice", `2,"bob, jr"`), root)
+
+	// Selected field, skipping header
+	b = merkletree.NewBuilder(merkletree.WithHashFunc(GetCustomHashFunc()))
+	header := true
+	added, err = b.IngestCSV(strings.NewReader(data), func(record []string) ([]byte, error) {
+		if header {
+			header = false
+			return nil, nil
+		}
+		return []byte(record[1]), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, added)
+	root, err = b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootOf(t, "alice", "bob, jr"), root)
+
+	// Test invalid CSV
+	b = merkletree.NewBuilder(merkletree.WithHashFunc(GetCustomHashFunc()))
+	_, err = b.IngestCSV(strings.NewReader("a,\"b\n"), nil)
+	assert.NotNil(t, err)
+	t.Log("invalid CSV, ingest failed as expected, err=", err)
+}
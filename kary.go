@@ -0,0 +1,279 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MaxArity is the max arity of a tree, which bounds the size of a group of
+// children hashed at a time
+const MaxArity = 256
+
+// checkArity returns error if k is not a valid arity
+func checkArity(k int) error {
+	if k < 2 || k > MaxArity {
+		return fmt.Errorf("invalid arity %d", k)
+	}
+
+	return nil
+}
+
+// buildKary build tree of arity > 2. A group of the last nodes of a level
+// shorter than arity is filled by repeating its last node, which is how
+// the last node of a binary tree is paired with itself.
+func (obj *Leaves) buildKary(opts Options) (*Tree, *Root, error) {
+	if err := checkArity(opts.Arity); err != nil {
+		return nil, nil, err
+	} else if err := opts.checkDuplicate(); err != nil {
+		return nil, nil, err
+	}
+	k := opts.Arity
+
+	tree, err := obj.initTree()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := make([]*Node, obj.Length())
+	for i := range *obj {
+		nodes[i] = &(*obj)[i]
+	}
+
+	for {
+		if err := opts.Context.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		branches := make([]*Node, 0, (len(nodes)+k-1)/k)
+		hashSet := make([]Hash, 0, cap(branches))
+		for i := 0; i < len(nodes); i += k {
+			end := i + k
+			if end > len(nodes) {
+				end = len(nodes)
+			}
+
+			group := nodes[i:end:end]
+			hashes := make([][]byte, len(group))
+			for j, node := range group {
+				hashes[j] = node.Hash
+			}
+
+			digest, err := hashGroup(opts.HashFunc, opts.NodeTag, hashes, k, opts.SortedPairHashing)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			branch := &Node{
+				Height:   group[0].Height + 1,
+				Hash:     digest,
+				Children: group,
+			}
+			branches = append(branches, branch)
+			hashSet = append(hashSet, digest)
+		}
+
+		*tree = append(*tree, hashSet)
+		nodes = branches
+
+		if len(nodes) == 1 {
+			break
+		}
+	}
+
+	root := nodes[0]
+	if opts.ParentLinks {
+		root.linkParents()
+	}
+
+	return tree, root, nil
+}
+
+// hashGroup returns digest of tag & the hashes of a group of children, which
+// is filled to k by repeating the last hash. Hashes are concatenated in
+// byte-sorted order if sorted is true.
+func hashGroup(h IHashFunc, tag []byte, hashes [][]byte, k int, sorted bool) ([]byte, error) {
+	if len(hashes) == 0 || len(hashes) > k {
+		return nil, &SizeError{Name: "size of group", Expected: uint64(k), Actual: uint64(len(hashes))}
+	}
+
+	group := make([][]byte, 0, k)
+	group = append(group, hashes...)
+	for len(group) < k {
+		group = append(group, hashes[len(hashes)-1])
+	}
+
+	if sorted {
+		sort.Slice(group, func(i, j int) bool {
+			return bytes.Compare(group[i], group[j]) < 0
+		})
+	}
+
+	size := len(tag)
+	for _, hash := range group {
+		size += len(hash)
+	}
+
+	msg := make([]byte, 0, size)
+	msg = append(msg, tag...)
+	for _, hash := range group {
+		msg = append(msg, hash...)
+	}
+
+	return h.Hash(msg)
+}
+
+// KaryProof is a self-contained merkle proof of tree of any arity
+type KaryProof struct {
+	// Arity of the tree
+	Arity int
+
+	// Index of the leaf
+	Index uint64
+
+	// Leaf hash
+	Leaf Hash
+
+	// Groups are the other nodes of the group of the node on each level,
+	// from leaf to the root. The position of the node in its group of level
+	// y is (Index / Arity^y) % Arity.
+	Groups [][]Hash
+}
+
+// GetKaryProof returns proof of the leaf at index in tree of arity k, e.g.
+// the tree built with WithArity(k). Binary trees are proved with k = 2, the
+// synthetic duplicate is rejected if the leaf count is configured by
+// WithLeafCount.
+func (tree *Tree) GetKaryProof(index uint64, k int, opt ...OptionFunc) (*KaryProof, error) {
+	if tree == nil || tree.Height() == 0 {
+		return nil, errors.New("tree is empty")
+	} else if err := checkArity(k); err != nil {
+		return nil, err
+	}
+
+	count, err := tree.leafCount(NewOptions(opt...))
+	if err != nil {
+		return nil, err
+	} else if index >= count {
+		return nil, &IndexError{Index: index, Count: count}
+	}
+
+	leaf, err := tree.GetHash(0, index)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &KaryProof{
+		Arity:  k,
+		Index:  index,
+		Leaf:   leaf,
+		Groups: make([][]Hash, 0, tree.Y()),
+	}
+
+	x := index
+	for y := uint64(0); y < tree.Y(); y++ {
+		start := x / uint64(k) * uint64(k)
+		end := start + uint64(k)
+		if end > tree.Width(y) {
+			end = tree.Width(y)
+		}
+
+		group := make([]Hash, 0, end-start-1)
+		for i := start; i < end; i++ {
+			if i != x {
+				group = append(group, (*tree)[y][i])
+			}
+		}
+		proof.Groups = append(proof.Groups, group)
+
+		x = PoN{y, x}.GetParentN(uint64(k))[1]
+	}
+
+	return proof, nil
+}
+
+// Verify returns true if the proof leads to root. Returns *IndexError if
+// Index is out of the tree of the groups, *SizeError if a hash of proof is
+// not of the digest size of h.
+func (proof *KaryProof) Verify(root []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	if proof == nil {
+		return false, errors.New("proof is empty")
+	} else if err := checkArity(proof.Arity); err != nil {
+		return false, err
+	}
+	opts := NewOptions(opt...)
+	if err := opts.checkDuplicate(); err != nil {
+		return false, err
+	}
+	k := uint64(proof.Arity)
+
+	size, err := hashSize(h)
+	if err != nil {
+		return false, err
+	} else if err := checkDigest("leaf", proof.Leaf, size); err != nil {
+		return false, err
+	}
+
+	digest := []byte(proof.Leaf)
+	x := proof.Index
+	for y, others := range proof.Groups {
+		if err := opts.Context.Err(); err != nil {
+			return false, err
+		}
+
+		pos := int(x % k)
+		if len(others) > proof.Arity-1 {
+			return false, &PathError{Step: y, PoN: PoN{uint64(y), x}, Err: &SizeError{Name: "number of other nodes of group", Expected: k - 1, Actual: uint64(len(others))}}
+		} else if pos > len(others) {
+			return false, &PathError{Step: y, PoN: PoN{uint64(y), x}, Err: errors.New("node is out of its group")}
+		}
+
+		for i, other := range others {
+			if err := checkDigest(fmt.Sprintf("node %d of group %d", i, y), other, size); err != nil {
+				return false, err
+			}
+		}
+
+		hashes := make([][]byte, 0, len(others)+1)
+		for _, other := range others[:pos] {
+			hashes = append(hashes, other)
+		}
+		hashes = append(hashes, digest)
+		for _, other := range others[pos:] {
+			hashes = append(hashes, other)
+		}
+
+		var err error
+		if digest, err = hashGroup(h, opts.NodeTag, hashes, proof.Arity, opts.SortedPairHashing); err != nil {
+			return false, err
+		}
+
+		x /= k
+	}
+
+	// The node of the root level must be the root
+	if x != 0 {
+		count := uint64(1)
+		for range proof.Groups {
+			if count > math.MaxUint64/k {
+				count = math.MaxUint64
+				break
+			}
+			count *= k
+		}
+		return false, &IndexError{Index: proof.Index, Count: count}
+	}
+
+	return bytes.Equal(root, digest), nil
+}
+
+// checkBinary returns error if arity of options is not 2
+func (opts *Options) checkBinary() error {
+	if opts.Arity != 0 && opts.Arity != 2 {
+		return fmt.Errorf("arity %d is not supported, only binary trees are", opts.Arity)
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build k-ary trees & verify proofs of all leaves
+func TestLeaves_BuildTree_WithArity(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, k := range []int{3, 4, 16} {
+		for _, sorted := range []bool{false, true} {
+			for _, n := range []int{1, 2, 3, 4, 5, 15, 16, 17, 100} {
+				opts := []merkletree.OptionFunc{
+					merkletree.WithHashFunc(h),
+					merkletree.WithArity(k),
+					merkletree.WithSortedPairHashing(sorted),
+				}
+				leaves := mockLeaves(n)
+				tree, root, err := leaves.BuildTree(opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, n, leaves.Length())
+
+				// Width of each level
+				width := uint64(n)
+				for y := uint64(0); y < tree.Height(); y++ {
+					assert.Equal(t, width, tree.Width(y))
+					width = (width + uint64(k) - 1) / uint64(k)
+				}
+				rootHash, err := tree.GetRootHash()
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, root.Hash, rootHash)
+				assert.Equal(t, int(tree.Y()), root.Height)
+				assert.LessOrEqual(t, len(root.Children), k)
+
+				for i := uint64(0); i < uint64(n); i++ {
+					proof, err := tree.GetKaryProof(i, k)
+					if err != nil {
+						t.Fatal(err)
+					}
+					assert.Equal(t, int(tree.Y()), len(proof.Groups))
+
+					result, err := proof.Verify(root.Hash, h, opts...)
+					if err != nil {
+						t.Fatal(err)
+					}
+					assert.True(t, result)
+
+					// Test tampered index of the same position in groups
+					leaf := proof.Leaf
+					count := uint64(1)
+					for range proof.Groups {
+						count *= uint64(k)
+					}
+					proof.Index = i + count
+					_, err = proof.Verify(root.Hash, h, opts...)
+					assert.IsType(t, &merkletree.IndexError{}, err, "k=%d n=%d index=%d", k, n, i)
+					proof.Index = i
+
+					proof.Leaf = badHash
+					result, err = proof.Verify(root.Hash, h, opts...)
+					if err != nil {
+						t.Fatal(err)
+					}
+					assert.False(t, result)
+					proof.Leaf = leaf
+				}
+			}
+		}
+	}
+}
+
+// Root of a 4-ary tree of 5 leaves
+func TestLeaves_BuildTree_WithArity_Root(t *testing.T) {
+	h := GetCustomHashFunc()
+	leaves := mockLeaves(5)
+	_, root, err := leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithArity(4), merkletree.WithParentLinks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashes := make([][]byte, 5)
+	for i, leaf := range *leaves {
+		hashes[i] = leaf.Hash
+		assert.NotNil(t, leaf.Parent)
+	}
+
+	// The last group is filled by repeating the last leaf
+	concat := func(parts ...[]byte) []byte {
+		var msg []byte
+		for _, part := range parts {
+			msg = append(msg, part...)
+		}
+		return msg
+	}
+	left, _ := h.Hash(concat(hashes[0], hashes[1], hashes[2], hashes[3]))
+	right, _ := h.Hash(concat(hashes[4], hashes[4], hashes[4], hashes[4]))
+	expected, _ := h.Hash(concat(left, right, right, right))
+	assert.Equal(t, expected, root.Hash)
+
+	// Marshal & unmarshal root with children
+	data, err := root.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &merkletree.Root{}
+	if err = decoded.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(decoded.Children))
+	assert.Equal(t, 4, len(decoded.Children[0].Children))
+
+	_, err = root.ToMatrix()
+	assert.NotNil(t, err)
+}
+
+// Prove binary trees with k-ary proofs of arity 2
+func TestTree_GetKaryProof_Binary(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, sorted := range []bool{false, true} {
+		for n := 1; n <= 17; n++ {
+			opt := merkletree.WithSortedPairHashing(sorted)
+			tree, root, err := mockLeaves(n).BuildTree(merkletree.WithHashFunc(h), opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := uint64(0); i < tree.Width(0); i++ {
+				proof, err := tree.GetKaryProof(i, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result, err := proof.Verify(root.Hash, h, opt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.True(t, result)
+			}
+		}
+	}
+}
+
+// Invalid arity & binary only features
+func TestWithArity_Invalid(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, k := range []int{-1, 1, merkletree.MaxArity + 1} {
+		_, _, err := mockLeaves(3).BuildTree(merkletree.WithHashFunc(h), merkletree.WithArity(k))
+		assert.NotNil(t, err)
+	}
+
+	tree, _, err := mockLeaves(3).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tree.GetKaryProof(0, 1)
+	assert.NotNil(t, err)
+	_, err = tree.GetKaryProof(100, 2)
+	assert.IsType(t, &merkletree.IndexError{}, err)
+
+	proof, err := tree.GetKaryProof(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Index = 1
+	proof.Groups[0] = nil
+	_, err = proof.Verify(badHash, h)
+	assert.IsType(t, &merkletree.PathError{}, err)
+
+	// Test untrusted arity & size of group
+	proof.Arity = 1 << 62
+	_, err = proof.Verify(badHash, h)
+	assert.NotNil(t, err)
+	t.Log("arity is too large, verify failed as expected, err=", err)
+
+	proof.Arity = 2
+	proof.Groups[0] = []merkletree.Hash{goodHash, goodHash}
+	_, err = proof.Verify(badHash, h)
+	assert.IsType(t, &merkletree.PathError{}, err)
+
+	opt := merkletree.WithArity(4)
+	_, err = mockLeaves(3).BuildTree32(merkletree.WithHashFunc(h), opt)
+	assert.NotNil(t, err)
+	_, err = merkletree.NewWindow(4, opt)
+	assert.NotNil(t, err)
+	assert.NotNil(t, merkletree.NewBuilder(opt).AddHash(goodHash))
+	t.Log("arity is not 2, binary only features failed as expected")
+
+	assert.Equal(t, merkletree.PoN{3, 2}, merkletree.PoN{2, 11}.GetParentN(4))
+}
+
+// Verify k-ary proof of tampered index
+func TestKaryProof_Verify_Index(t *testing.T) {
+	h := GetCustomHashFunc()
+	opt := merkletree.WithArity(4)
+	tree, root, err := mockLeaves(4).BuildTree(merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := tree.GetKaryProof(0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []uint64{4, 1 << 40} {
+		proof.Index = index
+		result, err := proof.Verify(root.Hash, h, opt)
+		assert.IsType(t, &merkletree.IndexError{}, err, "index=%d", index)
+		assert.False(t, result)
+		t.Log("index is tampered, verify failed as expected, err=", err)
+	}
+
+	proof.Index = 0
+	result, err := proof.Verify(root.Hash, h, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+}
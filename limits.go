@@ -0,0 +1,176 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// DecodeLimits limits of decoding untrusted artifacts, zero means no limit.
+// The limits of a tree & a proof are checked while decoding, before the
+// nodes exceeding them are allocated. A root & a merkle path are decoded in
+// full before they are checked, so memory of decoding them is only bounded
+// by MaxBytes.
+type DecodeLimits struct {
+	// MaxLevels is the max number of levels of a tree or a root, a merkle
+	// path or a proof has one less
+	MaxLevels int
+
+	// MaxNodes is the max number of nodes of a tree or a root
+	MaxNodes int
+
+	// MaxHashSize is the max size of a hash in bytes
+	MaxHashSize int
+
+	// MaxBytes is the max size of the input in bytes, and of the data
+	// decompressed from it
+	MaxBytes int
+}
+
+// DefaultDecodeLimits suggested limits of decoding untrusted artifacts,
+// which fit trees of up to 2^32 leaves with 512-bit hashes in 64 MiB
+var DefaultDecodeLimits = DecodeLimits{
+	MaxLevels:   33,
+	MaxNodes:    1 << 20,
+	MaxHashSize: 64,
+	MaxBytes:    64 << 20,
+}
+
+// checkBytes returns error if size exceeds MaxBytes
+func (limits DecodeLimits) checkBytes(size int) error {
+	return checkLimit("input size", limits.MaxBytes, uint64(size))
+}
+
+// checkHashSize returns error if size exceeds MaxHashSize
+func (limits DecodeLimits) checkHashSize(size uint64) error {
+	return checkLimit("hash size", limits.MaxHashSize, size)
+}
+
+// checkPathLength returns error if merkle path is longer than MaxLevels - 1
+func (limits DecodeLimits) checkPathLength(length uint64) error {
+	if limits.MaxLevels <= 0 {
+		return nil
+	}
+
+	return checkLimit("path length", limits.MaxLevels-1, length)
+}
+
+// decodeTree decode tree from JSON within limits, the number of levels &
+// nodes and the hash size are checked while decoding, before the nodes
+// exceeding them are allocated
+func (limits DecodeLimits) decodeTree(data []byte) (Tree, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	var tree Tree
+	if tok != nil {
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, errors.New("invalid tree")
+		}
+
+		tree = Tree{}
+		nodes := uint64(0)
+		for dec.More() {
+			if err := checkLimit("number of levels", limits.MaxLevels, uint64(len(tree)+1)); err != nil {
+				return nil, err
+			}
+
+			level, err := limits.decodeLevel(dec, &nodes)
+			if err != nil {
+				return nil, err
+			}
+			tree = append(tree, level)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected trailing data")
+	}
+
+	return tree, nil
+}
+
+// decodeLevel decode a level of tree within limits, nodes is the number of
+// nodes decoded so far
+func (limits DecodeLimits) decodeLevel(dec *json.Decoder, nodes *uint64) ([]Hash, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	} else if tok == nil {
+		return nil, nil
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("invalid level of tree")
+	}
+
+	level := []Hash{}
+	for dec.More() {
+		*nodes++
+		if err := checkLimit("number of nodes", limits.MaxNodes, *nodes); err != nil {
+			return nil, err
+		}
+
+		var hash Hash
+		if err := dec.Decode(&hash); err != nil {
+			return nil, err
+		} else if err := limits.checkHashSize(uint64(len(hash))); err != nil {
+			return nil, err
+		}
+		level = append(level, hash)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return level, nil
+}
+
+// checkRoot returns error if the node graph of root exceeds limits, the
+// graph must have been checked by checkGraph
+func (limits DecodeLimits) checkRoot(root *Root) error {
+	if limits.MaxLevels > 0 {
+		if err := root.checkGraph(limits.MaxLevels); err != nil {
+			return &LimitError{Name: "number of levels", Limit: uint64(limits.MaxLevels), Actual: uint64(limits.MaxLevels) + 1}
+		}
+	}
+
+	visited := make(map[*Node]bool)
+	stack := []*Node{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == nil || visited[node] {
+			continue
+		}
+		visited[node] = true
+
+		if err := checkLimit("number of nodes", limits.MaxNodes, uint64(len(visited))); err != nil {
+			return err
+		} else if err := limits.checkHashSize(uint64(len(node.Hash))); err != nil {
+			return err
+		}
+
+		stack = append(stack, node.children()...)
+	}
+
+	return nil
+}
+
+// checkLimit returns LimitError if actual exceeds limit, limit <= 0 means
+// no limit
+func checkLimit(name string, limit int, actual uint64) error {
+	if limit > 0 && actual > uint64(limit) {
+		return &LimitError{Name: name, Limit: uint64(limit), Actual: actual}
+	}
+
+	return nil
+}
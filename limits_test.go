@@ -0,0 +1,222 @@
+package merkletree_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Unmarshal artifacts within limits
+func TestWithDecodeLimits(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, root, err := mockLeaves(100).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeBytes, err := tree.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootBytes, err := root.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	merklePath, err := tree.PathForLeaf(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pathBytes, err := merklePath.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := tree.GetProof(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofBytes, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test default limits
+	opt := merkletree.WithDecodeLimits(merkletree.DefaultDecodeLimits)
+	tree2, err := merkletree.UnmarshalTree(treeBytes, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree, tree2)
+	_, err = merkletree.UnmarshalRoot(rootBytes, opt)
+	assert.Nil(t, err)
+	_, err = merkletree.UnmarshalPath(pathBytes, opt)
+	assert.Nil(t, err)
+	proof2, err := merkletree.UnmarshalProof(proofBytes, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, proof, proof2)
+
+	// Test exceeded limits
+	cases := []struct {
+		name   string
+		limits merkletree.DecodeLimits
+	}{
+		{"levels", merkletree.DecodeLimits{MaxLevels: int(tree.Height()) - 1}},
+		{"nodes", merkletree.DecodeLimits{MaxNodes: 100}},
+		{"hash size", merkletree.DecodeLimits{MaxHashSize: 20}},
+		{"bytes", merkletree.DecodeLimits{MaxBytes: 16}},
+	}
+	for _, c := range cases {
+		opt := merkletree.WithDecodeLimits(c.limits)
+
+		_, err = merkletree.UnmarshalTree(treeBytes, opt)
+		assert.IsType(t, &merkletree.LimitError{}, err, c.name)
+		t.Logf("%s exceeded, unmarshal tree failed as expected, err=%v", c.name, err)
+
+		_, err = merkletree.UnmarshalRoot(rootBytes, opt)
+		assert.IsType(t, &merkletree.LimitError{}, err, c.name)
+
+		if c.name != "nodes" && c.name != "hash size" {
+			_, err = merkletree.UnmarshalPath(pathBytes, opt)
+			assert.IsType(t, &merkletree.LimitError{}, err, c.name)
+		}
+
+		if c.name != "nodes" {
+			_, err = merkletree.UnmarshalProof(proofBytes, opt)
+			assert.IsType(t, &merkletree.LimitError{}, err, c.name)
+		}
+	}
+}
+
+// Unmarshal tree checking limits while decoding
+func TestUnmarshalTree_Limits(t *testing.T) {
+	// The second level is invalid after the limit of nodes is exceeded
+	data := []byte(`{"version":1,"kind":"tree","data":[["AA==","AQ=="],["Ag==",1]]}`)
+	_, err := merkletree.UnmarshalTree(data, merkletree.WithDecodeLimits(merkletree.DecodeLimits{MaxNodes: 2}))
+	assert.IsType(t, &merkletree.LimitError{}, err)
+	t.Log("nodes exceeded, unmarshal tree failed as expected, err=", err)
+
+	_, err = merkletree.UnmarshalTree(data, merkletree.WithDecodeLimits(merkletree.DecodeLimits{MaxLevels: 1}))
+	assert.IsType(t, &merkletree.LimitError{}, err)
+
+	_, err = merkletree.UnmarshalTree(data)
+	assert.NotNil(t, err)
+
+	// Test null & empty levels
+	for _, s := range []string{`null`, `[]`, `[null]`, `[[]]`} {
+		data := []byte(`{"version":1,"kind":"tree","data":` + s + `}`)
+		tree, err := merkletree.UnmarshalTree(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var expected merkletree.Tree
+		if err := json.Unmarshal([]byte(s), &expected); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, *tree, s)
+	}
+
+	// Test invalid trees
+	for _, s := range []string{`{}`, `[{}]`, `["AA=="]`, `[[1]]`} {
+		data := []byte(`{"version":1,"kind":"tree","data":` + s + `}`)
+		_, err := merkletree.UnmarshalTree(data)
+		assert.NotNil(t, err, s)
+	}
+}
+
+// Decode proof token within limits
+func TestDecodeProofToken_Limits(t *testing.T) {
+	h, err := merkletree.GetHashFunc(merkletree.HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := tree.NewProofToken(2, merkletree.HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := token.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, result, err := merkletree.VerifyProofToken(s, merkletree.WithDecodeLimits(merkletree.DefaultDecodeLimits))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	_, err = merkletree.DecodeProofToken(s, merkletree.WithDecodeLimits(merkletree.DecodeLimits{MaxHashSize: 16}))
+	assert.IsType(t, &merkletree.LimitError{}, err)
+
+	_, err = merkletree.DecodeProofToken(s, merkletree.WithDecodeLimits(merkletree.DecodeLimits{MaxBytes: 16}))
+	assert.IsType(t, &merkletree.LimitError{}, err)
+}
+
+// plainGzip gzip compressor without DecompressLimit
+type plainGzip struct {
+	c merkletree.GzipCompressor
+}
+
+func (g plainGzip) Compress(data []byte) ([]byte, error) {
+	return g.c.Compress(data)
+}
+
+func (g plainGzip) Decompress(data []byte) ([]byte, error) {
+	return g.c.Decompress(data)
+}
+
+// Reject decompression bomb
+func TestWithDecodeLimits_Decompression(t *testing.T) {
+	// A path of many zero positions compresses well
+	pons := make(merkletree.PoNs, 100000)
+	data, err := pons.MarshalVersioned(merkletree.WithCompression(merkletree.CompressionGzip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Compressed=", len(data))
+
+	limits := merkletree.DecodeLimits{MaxBytes: 64 << 10}
+	_, err = merkletree.UnmarshalPath(data, merkletree.WithDecodeLimits(limits))
+	assert.IsType(t, &merkletree.LimitError{}, err)
+	t.Log("decompressed size exceeded, unmarshal path failed as expected, err=", err)
+
+	_, err = merkletree.UnmarshalPath(data)
+	assert.Nil(t, err)
+
+	_, err = merkletree.Upgrade(merkletree.KindPath, data, merkletree.WithDecodeLimits(limits))
+	assert.IsType(t, &merkletree.LimitError{}, err)
+	t.Log("decompressed size exceeded, upgrade failed as expected, err=", err)
+
+	_, err = merkletree.Upgrade(merkletree.KindPath, data)
+	assert.Nil(t, err)
+
+	// Test decompressor without limit
+	merkletree.RegisterCompressor("plain-gzip", plainGzip{})
+	data, err = pons.MarshalVersioned(merkletree.WithCompression("plain-gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = merkletree.UnmarshalPath(data, merkletree.WithDecodeLimits(limits))
+	assert.IsType(t, &merkletree.LimitError{}, err)
+
+	// Test gzip directly
+	c := merkletree.GzipCompressor{}
+	compressed, err := c.Compress(bytes.Repeat([]byte{0}, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompressed, err := c.DecompressLimit(compressed, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1000, len(decompressed))
+	_, err = c.DecompressLimit(compressed, 999)
+	assert.IsType(t, &merkletree.LimitError{}, err)
+}
@@ -0,0 +1,73 @@
+package merkletree
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ToMatrix flattens the node graph of root into the coordinate tree, which
+// is the same as the one returned by BuildTree with root. So merkle paths,
+// GetHash & marshaling of Tree work on a root updated by Update, or decoded
+// from JSON. Hashes are shared with the nodes.
+//
+// A node paired with itself is recognized by its children being the same
+// pointer. Decoding from JSON makes them two equal subtrees, which can not
+// be told apart from distinct nodes of equal hashes, so they are flattened
+// as distinct nodes. The root hash & every path still verify.
+func (node *Root) ToMatrix() (*Tree, error) {
+	if node == nil {
+		return nil, errors.New("root is empty")
+	} else if err := node.checkGraph(MaxNodeDepth); err != nil {
+		return nil, err
+	}
+
+	levels := [][]*Node{{node}}
+	for depth := 0; ; depth++ {
+		level := levels[depth]
+		if len(level[0].Children) > 0 {
+			return nil, errors.New("node of k-ary tree is not supported")
+		}
+		if level[0].Left == nil && level[0].Right == nil {
+			break
+		}
+
+		children := make([]*Node, 0, 2*len(level))
+		for i, n := range level {
+			if n.Left == nil || n.Right == nil {
+				return nil, fmt.Errorf("node %d at depth %d has no children", i, depth)
+			}
+
+			children = append(children, n.Left)
+
+			// Node without brother is paired with itself, which must be the
+			// last node of the level
+			if n.Left == n.Right {
+				if i != len(level)-1 {
+					return nil, fmt.Errorf("node %d at depth %d is paired with itself but not the last one", i, depth)
+				}
+				continue
+			}
+			children = append(children, n.Right)
+		}
+
+		levels = append(levels, children)
+	}
+
+	leaves := levels[len(levels)-1]
+	for i, n := range leaves {
+		if n.Left != nil || n.Right != nil {
+			return nil, fmt.Errorf("leaf %d at depth %d has children", i, len(levels)-1)
+		}
+	}
+
+	tree := make(Tree, len(levels))
+	for depth, level := range levels {
+		hashSet := make([]Hash, len(level))
+		for i, n := range level {
+			hashSet[i] = n.Hash
+		}
+		tree[len(levels)-1-depth] = hashSet
+	}
+
+	return &tree, nil
+}
@@ -0,0 +1,107 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Flatten root into coordinate tree
+func TestRoot_ToMatrix(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, n := range []int{1, 2, 3, 5, 6, 7, 100} {
+		tree, root, err := mockLeaves(n).BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		matrix, err := root.ToMatrix()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tree, matrix)
+	}
+
+	// Test root decoded from JSON, the node paired with itself is flattened
+	// as two nodes
+	_, root, err := mockLeaves(6).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := root.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &merkletree.Root{}
+	if err = decoded.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	matrix, err := decoded.ToMatrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(4), matrix.Width(1))
+
+	rootHash, err := matrix.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rootHash)
+
+	bad, err := matrix.Verify(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, bad)
+
+	for i := uint64(0); i < 6; i++ {
+		proof, err := matrix.GetProof(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := proof.Verify(root.Hash, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result)
+	}
+
+	// Test root without child subtrees
+	matrix, err = (&merkletree.Root{Hash: root.Hash}).ToMatrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &merkletree.Tree{{root.Hash}}, matrix)
+}
+
+// Flatten malformed node graphs
+func TestRoot_ToMatrix_Error(t *testing.T) {
+	_, err := (*merkletree.Root)(nil).ToMatrix()
+	assert.NotNil(t, err)
+
+	leaf := &merkletree.Node{Hash: goodHash}
+
+	// Missing child
+	_, err = (&merkletree.Root{Height: 1, Left: leaf}).ToMatrix()
+	assert.NotNil(t, err)
+	t.Log("missing child, flatten failed as expected, err=", err)
+
+	// Node paired with itself is not the last one
+	self := &merkletree.Node{Height: 1, Left: leaf, Right: leaf}
+	pair := &merkletree.Node{Height: 1, Left: leaf, Right: &merkletree.Node{Hash: badHash}}
+	_, err = (&merkletree.Root{Height: 2, Left: self, Right: pair}).ToMatrix()
+	assert.NotNil(t, err)
+	t.Log("self-paired node in the middle, flatten failed as expected, err=", err)
+
+	// Leaves at different depths
+	_, err = (&merkletree.Root{Height: 2, Left: pair, Right: leaf}).ToMatrix()
+	assert.NotNil(t, err)
+	t.Log("unbalanced, flatten failed as expected, err=", err)
+
+	// Cycle
+	cycle := &merkletree.Root{}
+	cycle.Left, cycle.Right = cycle, cycle
+	_, err = cycle.ToMatrix()
+	assert.NotNil(t, err)
+}
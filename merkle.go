@@ -2,12 +2,18 @@ package merkletree
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"hash"
 	"sort"
+	"sync"
+)
 
-	"golang.org/x/crypto/sha3"
+var (
+	// defaultHashFunc set by SetDefaultHashFunc
+	defaultHashFunc   IHashFunc
+	defaultHashFuncMu sync.RWMutex
 )
 
 // HashProvider hash provider
@@ -38,11 +44,53 @@ func (h *HashFunc) Hash(msg []byte) ([]byte, error) {
 	return provider.Sum(nil), nil
 }
 
-// DefaultHashFunc returns default hash interface
+// Size returns digest size in bytes
+func (h *HashFunc) Size() int {
+	return h.Provider().Size()
+}
+
+// DoubleHashFunc hash function which hashes twice, e.g. SHA-256d of Bitcoin
+type DoubleHashFunc struct {
+	Provider HashProvider
+}
+
+// Hash returns digest of digest of message
+func (h *DoubleHashFunc) Hash(msg []byte) ([]byte, error) {
+	first := &HashFunc{Provider: h.Provider}
+
+	digest, err := first.Hash(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return first.Hash(digest)
+}
+
+// Size returns digest size in bytes
+func (h *DoubleHashFunc) Size() int {
+	return h.Provider().Size()
+}
+
+// DefaultHashFunc returns default hash interface, which is the one set by
+// SetDefaultHashFunc, or the built-in one if not set
 func DefaultHashFunc() IHashFunc {
-	hashFunc := new(HashFunc)
-	hashFunc.Provider = sha3.NewLegacyKeccak256
-	return hashFunc
+	defaultHashFuncMu.RLock()
+	defer defaultHashFuncMu.RUnlock()
+
+	if defaultHashFunc != nil {
+		return defaultHashFunc
+	}
+
+	return newDefaultHashFunc()
+}
+
+// SetDefaultHashFunc set package-level default hash interface, nil restores
+// the built-in one
+func SetDefaultHashFunc(h IHashFunc) {
+	defaultHashFuncMu.Lock()
+	defer defaultHashFuncMu.Unlock()
+
+	defaultHashFunc = h
 }
 
 // Hash node hash
@@ -58,6 +106,23 @@ type Node struct {
 	Left    *Node
 	Right   *Node
 	Payload []byte
+
+	// Meta is metadata of the node, excluded from hashing
+	Meta map[string][]byte `json:",omitempty"`
+
+	// Synthetic is true if the leaf is the duplicate of the last leaf, which
+	// is appended by BuildTree to make the number of leaves even
+	Synthetic bool `json:",omitempty"`
+
+	// Children of a branch of k-ary tree built with WithArity, Left & Right
+	// are nil
+	Children []*Node `json:",omitempty"`
+
+	// Parent of the node, set by BuildTree with WithParentLinks
+	Parent *Node `json:"-"`
+
+	// stream is payload reader set by SetPayloadReader
+	stream *payloadReader
 }
 
 // Leaf merkle tree leaf
@@ -73,7 +138,9 @@ func NewLeaf() Leaf {
 	}
 }
 
-// Clone returns a clone of the leaf
+// Clone returns a clone of the leaf. The payload reader set by
+// SetPayloadReader is not shared, hashing the clone fails until a reader is
+// set on it.
 func (node *Leaf) Clone() *Leaf {
 	if node == nil {
 		return nil
@@ -84,16 +151,76 @@ func (node *Leaf) Clone() *Leaf {
 	clone.Hash = node.Hash
 	clone.Left = node.Left
 	clone.Right = node.Right
+	clone.Children = node.Children
 	clone.Payload = node.Payload
+	clone.Meta = cloneMeta(node.Meta)
+	clone.Synthetic = node.Synthetic
+	clone.Parent = node.Parent
+	if node.stream != nil {
+		clone.stream = &payloadReader{size: node.stream.size, consumed: node.stream.consumed}
+	}
 
 	return &clone
 }
 
-// Marshal returns bytes of tree
+// cloneMeta returns a deep copy of meta
+func cloneMeta(meta map[string][]byte) map[string][]byte {
+	if meta == nil {
+		return nil
+	}
+
+	clone := make(map[string][]byte, len(meta))
+	for k, v := range meta {
+		if v != nil {
+			v = append(make([]byte, 0, len(v)), v...)
+		}
+		clone[k] = v
+	}
+
+	return clone
+}
+
+// Marshal returns bytes of tree, returns error if the node graph has a
+// cycle or is deeper than MaxNodeDepth
 func (node *Root) Marshal() ([]byte, error) {
+	if err := node.checkGraph(MaxNodeDepth); err != nil {
+		return nil, err
+	}
+
 	return json.Marshal(node)
 }
 
+// RootRecord compact record of root without child subtrees
+type RootRecord struct {
+	Height  int
+	Hash    []byte
+	Payload []byte            `json:",omitempty"`
+	Meta    map[string][]byte `json:",omitempty"`
+}
+
+// Record returns compact record of root
+func (node *Root) Record() *RootRecord {
+	if node == nil {
+		return nil
+	}
+
+	return &RootRecord{
+		Height:  node.Height,
+		Hash:    node.Hash,
+		Payload: node.Payload,
+		Meta:    cloneMeta(node.Meta),
+	}
+}
+
+// MarshalCompact returns bytes of root without child subtrees
+func (node *Root) MarshalCompact() ([]byte, error) {
+	if node == nil {
+		return nil, errors.New("root is empty")
+	}
+
+	return json.Marshal(node.Record())
+}
+
 // Leaves merkle tree leaves
 type Leaves []Leaf
 
@@ -106,6 +233,19 @@ func (obj *Leaves) Length() int {
 	return len(*obj)
 }
 
+// Count returns number of leaves excluding the synthetic duplicate appended
+// by BuildTree
+func (obj *Leaves) Count() int {
+	count := 0
+	for i := 0; i < obj.Length(); i++ {
+		if !(*obj)[i].Synthetic {
+			count++
+		}
+	}
+
+	return count
+}
+
 // IsEmpty returns if leaves if empty
 func (obj *Leaves) IsEmpty() bool {
 	return obj.Length() == 0
@@ -120,23 +260,63 @@ func (obj *Leaves) LastLeaf() *Leaf {
 	return &(*obj)[obj.Length()-1]
 }
 
-// BuildTree build tree by options, returns tree & root
+// BuildTree build tree by options, returns tree & root. Without leaves, a
+// tree of no levels & root of the empty hash are returned if AllowEmpty is
+// set. The tree of no levels has no place for the root, so it's reported by
+// GetRootHash with the same options, & it proves no leaf. Returns
+// *SizeError if a leaf hash, e.g. one set with SkipHash, is not of the
+// digest size of the hash function.
 func (obj *Leaves) BuildTree(opt ...OptionFunc) (*Tree, *Root, error) {
+	opts := NewOptions(opt...)
+	span := opts.startSpan(SpanBuildTree, Attribute{Key: AttrLeafCount, Value: obj.Length()})
+
+	tree, root, err := obj.buildTree(opts)
+	endSpan(span, err)
+
+	return tree, root, err
+}
+
+// buildTree build tree by options
+func (obj *Leaves) buildTree(opts Options) (*Tree, *Root, error) {
 	if obj == nil || obj.IsEmpty() {
-		return nil, nil, errors.New("not found leaf")
+		return buildEmptyTree(opts)
 	}
-	opts := NewOptions(opt...)
 
-	h := opts.HashFunc
+	if err := obj.checkBudget(opts); err != nil {
+		return nil, nil, err
+	}
 
 	if !opts.SkipHash {
-		if err := obj.Hash(h); err != nil {
+		if err := obj.hash(opts); err != nil {
 			return nil, nil, err
 		}
 	}
 
+	// Digests of different sizes lead to a meaningless root
+	size, err := opts.digestSize()
+	if err != nil {
+		return nil, nil, err
+	} else if err := obj.checkDigests(size); err != nil {
+		return nil, nil, err
+	}
+
+	if obj.Length() == 1 && opts.SingleLeafRoot {
+		return &Tree{{(*obj)[0].Hash}}, &(*obj)[0], nil
+	}
+
+	if opts.Arity != 0 && opts.Arity != 2 {
+		return obj.buildKary(opts)
+	}
+
+	if err := opts.checkOddRule(); err != nil {
+		return nil, nil, err
+	} else if opts.OddRule == OddPromote {
+		return obj.buildPromoted(opts)
+	}
+
 	if obj.Length()%2 == 1 {
 		clone := obj.LastLeaf().Clone()
+		clone.Synthetic = true
 		*obj = append(*obj, *clone)
 	}
 
@@ -145,23 +325,159 @@ func (obj *Leaves) BuildTree(opt ...OptionFunc) (*Tree, *Root, error) {
 		return nil, nil, err
 	}
 
-	root, err := obj.buildBranch(*obj, tree, h)
+	root, err := obj.buildBranch(*obj, tree, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if opts.ParentLinks {
+		root.linkParents()
+	}
+
 	return tree, root, nil
 }
 
+// buildEmptyTree returns tree without levels & root of the empty hash if
+// AllowEmpty is set
+func buildEmptyTree(opts Options) (*Tree, *Root, error) {
+	if !opts.AllowEmpty {
+		return nil, nil, errors.New("not found leaf")
+	}
+
+	digest, err := opts.emptyHash()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Tree{}, &Root{Hash: digest}, nil
+}
+
+// emptyHash returns root of a tree without leaves, the configured one must
+// be of the digest size of hash function
+func (opts *Options) emptyHash() ([]byte, error) {
+	if opts.EmptyHash != nil {
+		size, err := opts.digestSize()
+		if err != nil {
+			return nil, err
+		} else if err := checkDigest("empty hash", opts.EmptyHash, size); err != nil {
+			return nil, err
+		}
+		return opts.EmptyHash, nil
+	}
+
+	return opts.HashFunc.Hash(nil)
+}
+
+// linkParents set parent of all nodes under node
+func (node *Node) linkParents() {
+	for _, child := range node.children() {
+		if child != nil && child.Parent != node {
+			child.Parent = node
+			child.linkParents()
+		}
+	}
+}
+
+// LeafCount returns number of leaves under root excluding the synthetic
+// duplicate. A node paired with itself is recognized by pointer, so it's
+// counted twice in a root decoded from JSON.
+func (node *Root) LeafCount() uint64 {
+	if node == nil {
+		return 0
+	}
+
+	count := uint64(0)
+	visited := make(map[*Node]bool)
+	stack := []*Node{node}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == nil || visited[n] {
+			continue
+		}
+		visited[n] = true
+
+		if n.Left == nil && n.Right == nil && len(n.Children) == 0 {
+			if !n.Synthetic {
+				count++
+			}
+			continue
+		}
+		stack = append(stack, n.children()...)
+	}
+
+	return count
+}
+
+// children returns children of node, which are Children of a k-ary branch,
+// or Left & Right
+func (node *Node) children() []*Node {
+	if len(node.Children) > 0 {
+		return node.Children
+	}
+
+	return []*Node{node.Left, node.Right}
+}
+
+// Sibling returns the other child of parent, or the node itself if it's
+// paired with itself. Returns nil if parent is not linked.
+func (node *Node) Sibling() *Node {
+	if node == nil || node.Parent == nil {
+		return nil
+	} else if node.Parent.Left == node {
+		return node.Parent.Right
+	}
+
+	return node.Parent.Left
+}
+
+// BuildTreeContext build tree like BuildTree, the build is aborted if ctx
+// is done between leaves or levels
+func (obj *Leaves) BuildTreeContext(ctx context.Context, opt ...OptionFunc) (*Tree, *Root, error) {
+	return obj.BuildTree(append(opt, WithContext(ctx))...)
+}
+
 // Hash calc hash of leaves
 func (obj *Leaves) Hash(h IHashFunc) error {
+	return obj.hash(NewOptions(WithHashFunc(h)))
+}
+
+// hash calc hash of leaves by options, returns ctx error if ctx is done
+func (obj *Leaves) hash(opts Options) error {
 	for i := 0; i < obj.Length(); i++ {
-		digest, err := h.Hash((*obj)[i].Payload)
+		if err := opts.Context.Err(); err != nil {
+			return err
+		}
+
+		leaf := &(*obj)[i]
+
+		// Leaf of digest only, the payload is in the store, discarded or
+		// streamed by a previous build
+		if (opts.PayloadStore != nil || opts.DiscardPayload || leaf.consumed()) && leaf.Payload == nil && leaf.Hash != nil {
+			continue
+		}
+
+		if leaf.stream != nil && opts.PayloadStore != nil {
+			return errors.New("streamed payload can not be put into payload store")
+		}
+
+		digest, err := leaf.hashPayload(opts.HashFunc, opts.LeafTag)
 		if err != nil {
 			return err
 		}
 
-		(*obj)[i].Hash = digest
+		leaf.Hash = digest
+
+		if opts.PayloadStore != nil {
+			if err := opts.PayloadStore.Put(digest, leaf.Payload); err != nil {
+				return err
+			}
+			leaf.Payload = nil
+		}
+
+		if opts.DiscardPayload {
+			leaf.Payload = nil
+		}
 	}
 
 	return nil
@@ -172,14 +488,47 @@ func (obj *Leaves) Sort() {
 	sort.Sort(obj)
 }
 
-// Add leaf to leaves
+// SortStable sort leaves by hash, leaves of equal hashes are sorted by
+// payload, then kept in their original order, so the order is canonical
+func (obj *Leaves) SortStable() {
+	if obj == nil {
+		return
+	}
+
+	sort.SliceStable(*obj, func(i, j int) bool {
+		a, b := &(*obj)[i], &(*obj)[j]
+		if c := bytes.Compare(a.Hash, b.Hash); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(a.Payload, b.Payload) < 0
+	})
+}
+
+// Add leaf to leaves, the synthetic duplicate appended by BuildTree is
+// replaced by the leaf
 func (obj *Leaves) Add(leaf *Leaf) {
 	if obj == nil || leaf == nil {
 		return
 	}
+
+	if !obj.IsEmpty() && obj.LastLeaf().Synthetic {
+		*obj = (*obj)[:obj.Length()-1]
+	}
 	*obj = append(*obj, *leaf)
 }
 
+// Originals returns leaves excluding the synthetic duplicate appended by
+// BuildTree, which shares the underlying array with leaves
+func (obj *Leaves) Originals() Leaves {
+	if obj.IsEmpty() {
+		return nil
+	} else if obj.LastLeaf().Synthetic {
+		return (*obj)[:obj.Length()-1]
+	}
+
+	return *obj
+}
+
 // Clone returns a clone of the leaves
 func (obj *Leaves) Clone() *Leaves {
 	if obj == nil {
@@ -219,7 +568,11 @@ func (obj *Leaves) initTree() (*Tree, error) {
 }
 
 // buildBranch build branch, fill the tree & returns root
-func (obj *Leaves) buildBranch(nodes []Node, tree *Tree, h IHashFunc) (*Root, error) {
+func (obj *Leaves) buildBranch(nodes []Node, tree *Tree, opts Options) (*Root, error) {
+	if err := opts.Context.Err(); err != nil {
+		return nil, err
+	}
+
 	var branches []Node
 	var hashSet []Hash
 
@@ -231,7 +584,7 @@ func (obj *Leaves) buildBranch(nodes []Node, tree *Tree, h IHashFunc) (*Root, er
 			right = i
 		}
 
-		digest, err := h.Hash(append(nodes[left].Hash, nodes[right].Hash...))
+		digest, err := hashPair(opts.HashFunc, opts.NodeTag, nodes[left].Hash, nodes[right].Hash, opts.SortedPairHashing)
 		if err != nil {
 			return nil, err
 		}
@@ -254,7 +607,35 @@ func (obj *Leaves) buildBranch(nodes []Node, tree *Tree, h IHashFunc) (*Root, er
 
 	*tree = append(*tree, hashSet)
 
-	return obj.buildBranch(branches, tree, h)
+	return obj.buildBranch(branches, tree, opts)
+}
+
+// hashLeaf returns digest of tag & payload
+func hashLeaf(h IHashFunc, tag []byte, payload []byte) ([]byte, error) {
+	if len(tag) == 0 {
+		return h.Hash(payload)
+	}
+
+	msg := make([]byte, 0, len(tag)+len(payload))
+	msg = append(msg, tag...)
+	msg = append(msg, payload...)
+
+	return h.Hash(msg)
+}
+
+// hashPair returns digest of tag, left & right, children are concatenated
+// in byte-sorted order if sorted is true
+func hashPair(h IHashFunc, tag []byte, left []byte, right []byte, sorted bool) ([]byte, error) {
+	if sorted && bytes.Compare(left, right) > 0 {
+		left, right = right, left
+	}
+
+	msg := make([]byte, 0, len(tag)+len(left)+len(right))
+	msg = append(msg, tag...)
+	msg = append(msg, left...)
+	msg = append(msg, right...)
+
+	return h.Hash(msg)
 }
 
 /***************************
@@ -279,6 +660,24 @@ func (tree *Tree) Marshal() ([]byte, error) {
 	return json.Marshal(tree)
 }
 
+// Clone returns a deep copy of the tree, the clone shares no hash with the
+// original tree
+func (tree *Tree) Clone() *Tree {
+	if tree == nil {
+		return nil
+	}
+
+	clone := make(Tree, tree.Height())
+	for y, level := range *tree {
+		clone[y] = make([]Hash, len(level))
+		for x, hash := range level {
+			clone[y][x] = append(Hash{}, hash...)
+		}
+	}
+
+	return &clone
+}
+
 // Height returns height of tree
 func (tree *Tree) Height() uint64 {
 	if tree == nil {
@@ -312,10 +711,16 @@ func (tree *Tree) X(y uint64) uint64 {
 	return tree.Width(y) - 1
 }
 
-// GetRootHash returns root hash
-func (tree *Tree) GetRootHash() ([]byte, error) {
+// GetRootHash returns root hash. The empty hash is returned for a tree of no
+// levels if AllowEmpty is set, which is the root built by BuildTree without
+// leaves.
+func (tree *Tree) GetRootHash(opt ...OptionFunc) ([]byte, error) {
 	if tree == nil || tree.Height() == 0 {
-		return nil, errors.New("tree is empty")
+		opts := NewOptions(opt...)
+		if !opts.AllowEmpty {
+			return nil, errors.New("tree is empty")
+		}
+		return opts.emptyHash()
 	}
 
 	return (*tree)[tree.Y()][0], nil
@@ -325,41 +730,224 @@ func (tree *Tree) GetRootHash() ([]byte, error) {
 func (tree *Tree) GetHash(y uint64, x uint64) ([]byte, error) {
 	if tree == nil || tree.Height() == 0 {
 		return nil, errors.New("tree is empty")
-	} else if y > tree.Y() {
-		return nil, errors.New("invalid y")
-	} else if x > tree.X(y) {
-		return nil, errors.New("invalid x")
+	} else if y > tree.Y() || x > tree.X(y) {
+		return nil, tree.positionError(y, x)
 	}
 
 	return (*tree)[y][x], nil
 }
 
-// Prove returns merkle proofs result
-func (tree *Tree) Prove(merklePath *PoNs, unverifiedHash []byte, h IHashFunc) (bool, error) {
+// GetLevel returns a copy of hashes of level y
+func (tree *Tree) GetLevel(y uint64) ([]Hash, error) {
+	if tree == nil || tree.Height() == 0 {
+		return nil, errors.New("tree is empty")
+	} else if y > tree.Y() {
+		return nil, tree.positionError(y, 0)
+	}
+
+	level := make([]Hash, tree.Width(y))
+	for x, hash := range (*tree)[y] {
+		level[x] = append(Hash{}, hash...)
+	}
+
+	return level, nil
+}
+
+// LeafIndex returns index of the first leaf matching hash. The synthetic
+// duplicate is excluded if the leaf count is configured by WithLeafCount
+func (tree *Tree) LeafIndex(hash []byte, opt ...OptionFunc) (uint64, error) {
+	indexes := tree.LeafIndexes(hash, opt...)
+	if len(indexes) == 0 {
+		return 0, errors.New("not found leaf")
+	}
+
+	return indexes[0], nil
+}
+
+// LeafIndexes returns indexes of all leaves matching hash, in ascending
+// order. The synthetic duplicate is excluded if the leaf count is
+// configured by WithLeafCount
+func (tree *Tree) LeafIndexes(hash []byte, opt ...OptionFunc) []uint64 {
+	if tree == nil || tree.Height() == 0 {
+		return nil
+	}
+
+	width, err := tree.leafCount(NewOptions(opt...))
+	if err != nil {
+		return nil
+	}
+
+	var indexes []uint64
+	for x, leafHash := range (*tree)[0][:width] {
+		if bytes.Equal(leafHash, hash) {
+			indexes = append(indexes, uint64(x))
+		}
+	}
+
+	return indexes
+}
+
+// PathForLeaf returns merkle path of the leaf at index, from leaf to the
+// root. A node without brother on a level of odd width is paired with
+// itself, so the path refers to the node itself on that level, or the level
+// is skipped if the odd rule is OddPromote. The path of a tree with only one
+// level is empty. The synthetic duplicate is rejected if the leaf count is
+// configured by WithLeafCount.
+func (tree *Tree) PathForLeaf(index uint64, opt ...OptionFunc) (PoNs, error) {
+	if tree == nil || tree.Height() == 0 {
+		return nil, &IndexError{Index: index, Count: 0}
+	}
+
+	opts := NewOptions(opt...)
+	count, err := tree.leafCount(opts)
+	if err != nil {
+		return nil, err
+	} else if index >= count {
+		return nil, &IndexError{Index: index, Count: count}
+	}
+
+	return tree.pathFrom(0, index, opts.OddRule == OddPromote), nil
+}
+
+// leafCount returns number of leaves configured by options, or width of
+// the leaf level
+func (tree *Tree) leafCount(opts Options) (uint64, error) {
+	return opts.leafCount(tree.Width(0))
+}
+
+// leafCount returns number of leaves configured by options, or width of
+// the leaf level. The configured count must be the width, or one less if
+// the last leaf is the synthetic duplicate.
+func (opts *Options) leafCount(width uint64) (uint64, error) {
+	count := opts.LeafCount
+
+	switch {
+	case count == 0:
+		return width, nil
+	case count == width, count%2 == 1 && count+1 == width:
+		return count, nil
+	default:
+		return 0, &SizeError{Name: "leaf count", Expected: width, Actual: count}
+	}
+}
+
+// pathFrom returns merkle path of node (y,x), from the node to the root. A
+// node without brother is skipped if promote is set
+func (tree *Tree) pathFrom(y uint64, x uint64, promote bool) PoNs {
+	pons := make(PoNs, 0, tree.Y()-y)
+	for ; y < tree.Y(); y++ {
+		brother := x ^ 1
+		if brother > tree.X(y) {
+			if promote {
+				x /= 2
+				continue
+			}
+			brother = x
+		}
+		pons = append(pons, PoN{y, brother})
+		x /= 2
+	}
+
+	return pons
+}
+
+// Prove returns merkle proofs result. Options are used to configure how
+// pairs are hashed, they should be the same as the ones used by BuildTree
+func (tree *Tree) Prove(merklePath *PoNs, unverifiedHash []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	opts := NewOptions(append(opt, WithHashFunc(h))...)
+	span := opts.startSpan(SpanProve, Attribute{Key: AttrPathLength, Value: pathLength(merklePath)})
+
+	result, err := tree.prove(merklePath, unverifiedHash, h, opts)
+	endSpan(span, err, Attribute{Key: AttrResult, Value: result})
+
+	return result, err
+}
+
+// ProveContext returns merkle proofs result like Prove, the proof is
+// aborted if ctx is done between steps of the path
+func (tree *Tree) ProveContext(ctx context.Context, merklePath *PoNs, unverifiedHash []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	return tree.Prove(merklePath, unverifiedHash, h, append(opt, WithContext(ctx))...)
+}
+
+// ProveAgainstRootContext returns merkle proofs result like
+// ProveAgainstRoot, the proof is aborted if ctx is done between steps of
+// the path
+func (tree *Tree) ProveAgainstRootContext(ctx context.Context, merklePath *PoNs, unverifiedHash []byte, root []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	return tree.ProveAgainstRoot(merklePath, unverifiedHash, root, h, append(opt, WithContext(ctx))...)
+}
+
+// prove returns merkle proofs result against root of tree
+func (tree *Tree) prove(merklePath *PoNs, unverifiedHash []byte, h IHashFunc, opts Options) (bool, error) {
+	digest, err := tree.computeRoot(merklePath, unverifiedHash, h, opts)
+	if err != nil {
+		return false, err
+	}
+
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Compare(rootHash, digest) == 0, nil
+}
+
+// ProveAgainstRoot returns merkle proofs result like Prove, but compares the
+// derived root with the trusted root instead of the one stored in the tree.
+// The tree only supplies brothers of the path, so it can be untrusted
+func (tree *Tree) ProveAgainstRoot(merklePath *PoNs, unverifiedHash []byte, root []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	opts := NewOptions(append(opt, WithHashFunc(h))...)
+	span := opts.startSpan(SpanProveAgainstRoot, Attribute{Key: AttrPathLength, Value: pathLength(merklePath)})
+
+	result, err := tree.proveAgainstRoot(merklePath, unverifiedHash, root, h, opts)
+	endSpan(span, err, Attribute{Key: AttrResult, Value: result})
+
+	return result, err
+}
+
+// proveAgainstRoot returns merkle proofs result against root
+func (tree *Tree) proveAgainstRoot(merklePath *PoNs, unverifiedHash []byte, root []byte, h IHashFunc, opts Options) (bool, error) {
+	if len(root) == 0 {
+		return false, errors.New("root is empty")
+	}
+
+	digest, err := tree.computeRoot(merklePath, unverifiedHash, h, opts)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Compare(root, digest) == 0, nil
+}
+
+// computeRoot returns root hash derived from unverifiedHash & brothers
+// along the merkle path
+func (tree *Tree) computeRoot(merklePath *PoNs, unverifiedHash []byte, h IHashFunc, opts Options) ([]byte, error) {
+	if merklePath == nil {
+		return nil, errors.New("path is empty")
+	}
+
 	digest := unverifiedHash
 
-	for _, pon := range *merklePath {
+	for i, pon := range *merklePath {
+		if err := opts.Context.Err(); err != nil {
+			return nil, err
+		}
+
 		brother, err := tree.GetHash(pon[0], pon[1])
 		if err != nil {
-			return false, err
+			return nil, &PathError{Step: i, PoN: pon, Err: err}
 		}
 
 		if pon[1]%2 == 0 {
-			digest, err = h.Hash(append(brother, digest...))
+			digest, err = hashPair(h, opts.NodeTag, brother, digest, opts.SortedPairHashing)
 		} else {
-			digest, err = h.Hash(append(digest, brother...))
+			digest, err = hashPair(h, opts.NodeTag, digest, brother, opts.SortedPairHashing)
 		}
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 	}
 
-	rootHash, err := tree.GetRootHash()
-	if err != nil {
-		return false, err
-	}
-
-	return bytes.Compare(rootHash, digest) == 0, nil
+	return digest, nil
 }
 
 // PoN is position of node, PoN[0] is y, PoN[1] is x
@@ -373,6 +961,76 @@ func (pon PoN) GetParent() PoN {
 	return PoN{pon[0] + 1, pon[1] / 2}
 }
 
+// GetParentN returns parent of node in tree of arity k
+func (pon PoN) GetParentN(k uint64) PoN {
+	return PoN{pon[0] + 1, pon[1] / k}
+}
+
+// Validate returns error if the merkle path does not fit the tree: every
+// position must be in range, the first one must be on the leaf level, each
+// position must be the brother of the parent of the previous one, and the
+// last one must be right below the root. The path is empty only if the tree
+// has one level. If the odd rule is OddPromote, the parent is the ancestor
+// the node is promoted to, and the path of the last leaf starts on the level
+// it's promoted to.
+func (pons *PoNs) Validate(tree *Tree, opt ...OptionFunc) error {
+	promote := NewOptions(opt...).OddRule == OddPromote
+	if pons == nil {
+		return errors.New("path is empty")
+	} else if tree == nil || tree.Height() == 0 {
+		return errors.New("tree is empty")
+	} else if len(*pons) == 0 && tree.Height() > 1 {
+		return errors.New("path is empty but the tree has more than one level")
+	}
+
+	for i, pon := range *pons {
+		if pon[0] >= tree.Y() || pon[1] > tree.X(pon[0]) {
+			return &PathError{Step: i, PoN: pon, Err: tree.positionError(pon[0], pon[1])}
+		}
+
+		if i == 0 {
+			if pon[0] == 0 {
+				continue
+			}
+
+			last := PoN{0, tree.X(0)}
+			if promote {
+				last = tree.promoted(last)
+			}
+			if pon[0] != last[0] || pon[1] != last[1]^1 {
+				return &PathError{Step: i, PoN: pon, Err: errors.New("path does not start on the leaf level")}
+			}
+			continue
+		}
+
+		prev := (*pons)[i-1]
+		parent := prev.GetParent()
+		if promote {
+			parent = tree.promoted(parent)
+		}
+		brother := parent[1] ^ 1
+		if brother > tree.X(parent[0]) {
+			brother = parent[1]
+		}
+
+		if pon[0] != parent[0] || pon[1] != brother {
+			return &PathError{Step: i, PoN: pon, Err: errors.New("not the brother of the parent of the previous position")}
+		}
+	}
+
+	if last := len(*pons) - 1; last >= 0 {
+		top := (*pons)[last].GetParent()
+		if promote {
+			top = tree.promoted(top)
+		}
+		if top[0] != tree.Y() {
+			return &PathError{Step: last, PoN: (*pons)[last], Err: errors.New("path does not reach the root")}
+		}
+	}
+
+	return nil
+}
+
 // GetPath returns merkle path, pons is Positions Of Nodes
 func (pons *PoNs) GetPath(height uint64, y uint64, x uint64) {
 	pon := PoN{y}
@@ -1,9 +1,12 @@
 package merkletree_test
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/jovijovi/merkletree"
 	"github.com/stretchr/testify/assert"
@@ -312,6 +315,54 @@ func TestTree_Prove(t *testing.T) {
 	assert.Equal(t, false, resultBad)
 }
 
+// Merkle proofs against a trusted root
+func TestTree_ProveAgainstRoot(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merklePath, err := tree.PathForLeaf(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := tree.ProveAgainstRoot(&merklePath, goodHash, root.Hash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Test forged tree, whose stored root matches the forged path
+	forged := tree.Clone()
+	leafHash, _ := h.Hash([]byte("forged"))
+	(*forged)[0][2] = leafHash
+	if _, err = forged.Repair(h); err != nil {
+		t.Fatal(err)
+	}
+	result, err = forged.Prove(&merklePath, leafHash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	result, err = forged.ProveAgainstRoot(&merklePath, leafHash, root.Hash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, result)
+	t.Log("forged tree, prove against root failed as expected")
+
+	// Test empty root
+	_, err = tree.ProveAgainstRoot(&merklePath, goodHash, nil, h)
+	assert.NotNil(t, err)
+
+	// Test empty path
+	_, err = tree.ProveAgainstRoot(nil, goodHash, root.Hash, h)
+	assert.NotNil(t, err)
+}
+
 // Clone leaf
 func TestLeaf_Clone(t *testing.T) {
 	clone1 := MockLeaves[0].Clone()
@@ -525,3 +576,779 @@ func TestTree_X(t *testing.T) {
 	}
 	assert.Zero(t, x3)
 }
+
+// Build tree with sorted pair hashing & prove without position
+func TestLeaves_BuildTree_WithSortedPairHashing(t *testing.T) {
+	leaves := MockLeaves.Clone()
+	h := GetCustomHashFunc()
+
+	// Build tree
+	tree, root, err := leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithSortedPairHashing(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("RootHash(hex)=", merkletree.Hex(root.Hash))
+
+	// Get merkle path
+	merklePath := make(merkletree.PoNs, 0)
+	merklePath.GetPath(tree.Height(), 0, 2)
+
+	// Prove with sorted pair hashing
+	result, err := tree.Prove(&merklePath, goodHash, h, merkletree.WithSortedPairHashing(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Commutative verifier, position indexes are not used
+	digest := goodHash
+	for _, pon := range merklePath {
+		brother, err := tree.GetHash(pon[0], pon[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pair := append([]byte{}, digest...)
+		if bytes.Compare(brother, digest) < 0 {
+			pair = append(append([]byte{}, brother...), digest...)
+		} else {
+			pair = append(pair, brother...)
+		}
+		if digest, err = h.Hash(pair); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, root.Hash, digest)
+
+	// Prove without sorted pair hashing
+	result, err = tree.Prove(&merklePath, goodHash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, result)
+}
+
+// Clone tree
+func TestTree_Clone(t *testing.T) {
+	leaves := MockLeaves.Clone()
+
+	// Build tree
+	tree1, _, err := leaves.BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test clone tree
+	tree2 := tree1.Clone()
+	assert.Equal(t, *tree1, *tree2)
+
+	// Mutate the clone, the original tree should be unchanged
+	hash, err := tree2.GetHash(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash[0] ^= 0xff
+	assert.NotEqual(t, *tree1, *tree2)
+
+	// Test invalid tree
+	var invalidTree1 *merkletree.Tree
+	tree3 := invalidTree1.Clone()
+	if tree3 == nil {
+		t.Log("tree is nil, clone failed as expected")
+	}
+	assert.Nil(t, tree3)
+}
+
+// Build tree with context
+func TestLeaves_BuildTreeContext(t *testing.T) {
+	leaves := MockLeaves.Clone()
+
+	// Build tree with live context
+	tree1, root1, err := leaves.BuildTreeContext(context.Background(), merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Tree1=", tree1)
+	t.Log("Root1=", root1)
+
+	// Build tree with canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = MockLeaves.Clone().BuildTreeContext(ctx)
+	if err != nil {
+		t.Log("context is canceled, build tree failed as expected")
+	}
+	assert.Equal(t, context.Canceled, err)
+
+	// Build tree with canceled context & skip hash
+	_, _, err = leaves.BuildTree(merkletree.WithSkipHash(true), merkletree.WithContext(ctx))
+	assert.Equal(t, context.Canceled, err)
+}
+
+// Prove with context
+func TestTree_ProveContext(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merklePath, err := tree.PathForLeaf(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Prove with live context
+	result, err := tree.ProveContext(context.Background(), &merklePath, goodHash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	result, err = tree.ProveAgainstRootContext(context.Background(), &merklePath, goodHash, root.Hash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Prove with expired deadline
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = tree.ProveContext(ctx, &merklePath, goodHash, h)
+	if err != nil {
+		t.Log("deadline exceeded, prove failed as expected")
+	}
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	_, err = tree.ProveAgainstRootContext(ctx, &merklePath, goodHash, root.Hash, h)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// Verify & audit with expired deadline
+	_, err = tree.Verify(h, merkletree.WithContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	_, err = tree.AuditSample(3, h, nil, merkletree.WithContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+// Get leaf index by hash
+func TestTree_LeafIndex(t *testing.T) {
+	leaves := MockLeaves.Clone()
+
+	// Build tree, the last leaf is duplicated
+	tree, _, err := leaves.BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test get index
+	index, err := tree.LeafIndex(goodHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Index=", index)
+	assert.Equal(t, uint64(2), index)
+
+	// Test get indexes of duplicate leaves
+	lastHash, err := tree.GetHash(0, tree.X(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes := tree.LeafIndexes(lastHash)
+	t.Log("Indexes=", indexes)
+	assert.Equal(t, []uint64{8, 9}, indexes)
+
+	// Test hash not found
+	_, err = tree.LeafIndex(badHash)
+	if err != nil {
+		t.Log("hash not found, get index failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test invalid tree
+	var invalidTree1 *merkletree.Tree
+	_, err = invalidTree1.LeafIndex(goodHash)
+	assert.NotNil(t, err)
+	assert.Nil(t, invalidTree1.LeafIndexes(goodHash))
+}
+
+// Calculate merkle path by leaf index
+func TestTree_PathForLeaf(t *testing.T) {
+	h := GetCustomHashFunc()
+
+	// Test trees of many sizes, including single-leaf & two-leaf trees
+	for size := 1; size <= 17; size++ {
+		leaves := MockLeaves.Clone()
+		for leaves.Length() < size {
+			leaves.Add(&merkletree.Leaf{Payload: []byte{byte(leaves.Length())}})
+		}
+		*leaves = (*leaves)[:size]
+
+		tree, root, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for index := uint64(0); index <= tree.X(0); index++ {
+			merklePath, err := tree.PathForLeaf(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, int(tree.Y()), len(merklePath))
+
+			leafHash, err := tree.GetHash(0, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := tree.Prove(&merklePath, leafHash, h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, result, "size=%d index=%d", size, index)
+
+			result, err = tree.Prove(&merklePath, root.Hash, h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.False(t, result, "size=%d index=%d", size, index)
+		}
+	}
+
+	// Test single-level tree, the path is empty
+	singleLevelTree := merkletree.Tree{[]merkletree.Hash{goodHash}}
+	merklePath, err := singleLevelTree.PathForLeaf(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, merklePath)
+
+	// Test invalid index
+	_, err = singleLevelTree.PathForLeaf(1)
+	if err != nil {
+		t.Log("index is out of range, get path failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test invalid tree
+	var invalidTree1 *merkletree.Tree
+	_, err = invalidTree1.PathForLeaf(0)
+	if err != nil {
+		t.Log("tree is nil, get path failed as expected")
+	}
+	assert.NotNil(t, err)
+}
+
+// Set package-level default hash func
+func TestSetDefaultHashFunc(t *testing.T) {
+	builtin := merkletree.DefaultHashFunc()
+
+	// Build tree with custom hash func
+	_, root1, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Build tree with custom default hash func
+	merkletree.SetDefaultHashFunc(GetCustomHashFunc())
+	_, root2, err := MockLeaves.Clone().BuildTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root1.Hash, root2.Hash)
+
+	// Restore built-in default hash func
+	merkletree.SetDefaultHashFunc(nil)
+	_, root3, err := MockLeaves.Clone().BuildTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, root4, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(builtin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root4.Hash, root3.Hash)
+}
+
+// Root compact marshal
+func TestRoot_MarshalCompact(t *testing.T) {
+	_, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := root.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compact, err := root.MarshalCompact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("RootMarshalCompactString=", string(compact))
+	assert.Less(t, len(compact), len(full))
+
+	var record merkletree.RootRecord
+	if err := json.Unmarshal(compact, &record); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *root.Record(), record)
+	assert.Equal(t, root.Hash, record.Hash)
+	assert.Equal(t, root.Height, record.Height)
+
+	// Test invalid root
+	var invalidRoot *merkletree.Root
+	_, err = invalidRoot.MarshalCompact()
+	if err != nil {
+		t.Log("root is nil, marshal failed as expected")
+	}
+	assert.NotNil(t, err)
+	assert.Nil(t, invalidRoot.Record())
+}
+
+// Build tree & discard payloads
+func TestLeaves_BuildTree_WithDiscardPayload(t *testing.T) {
+	_, root1, err := MockLeaves.Clone().BuildTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaves := MockLeaves.Clone()
+	_, root2, err := leaves.BuildTree(merkletree.WithDiscardPayload(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root1.Hash, root2.Hash)
+
+	for _, leaf := range *leaves {
+		assert.Nil(t, leaf.Payload)
+		assert.NotNil(t, leaf.Hash)
+	}
+	assert.Nil(t, root2.Left.Left.Left.Left.Payload)
+
+	// Rebuild with the digests kept
+	_, root3, err := leaves.BuildTree(merkletree.WithDiscardPayload(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root1.Hash, root3.Hash)
+}
+
+// Get copy of level
+func TestTree_GetLevel(t *testing.T) {
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	level, err := tree.GetLevel(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, (*tree)[0], level)
+
+	// Mutate the copy, the tree should be unchanged
+	level[2][0] ^= 0xff
+	hash, err := tree.GetHash(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, goodHash, hash)
+
+	// Test invalid y
+	_, err = tree.GetLevel(tree.Height())
+	if err != nil {
+		t.Log("y is out of range, get level failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test invalid tree
+	var invalidTree1 *merkletree.Tree
+	_, err = invalidTree1.GetLevel(0)
+	assert.NotNil(t, err)
+}
+
+// Validate merkle path against tree
+func TestPoNs_Validate(t *testing.T) {
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test valid paths
+	for index := uint64(0); index <= tree.X(0); index++ {
+		merklePath, err := tree.PathForLeaf(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, merklePath.Validate(tree), "index=%d", index)
+	}
+
+	// Test empty path, which is valid only if the leaf is the root
+	err = (&merkletree.PoNs{}).Validate(tree)
+	assert.NotNil(t, err)
+	t.Log("path is empty, validate failed as expected, err=", err)
+
+	single, _, err := mockLeaves(1).BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()), merkletree.WithSingleLeafRoot(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, (&merkletree.PoNs{}).Validate(single))
+
+	// Test invalid paths
+	invalidPaths := []merkletree.PoNs{
+		{{0, 10}},
+		{{tree.Y(), 0}},
+		{{0, 1}, {1, 0}, {2, 1}, {3, 1}},
+		{{0, 1}, {2, 1}, {3, 1}},
+		{{0, 1}, {1, 1}},
+		{{0, 9}, {1, 3}, {2, 1}, {3, 0}},
+		{{2, 1}},
+		{{3, 1}},
+		{{1, 3}, {2, 0}, {3, 1}},
+	}
+	for _, merklePath := range invalidPaths {
+		err := merklePath.Validate(tree)
+		if err != nil {
+			t.Log("path is invalid, validate failed as expected, err=", err)
+		}
+		assert.NotNil(t, err, "path=%v", merklePath)
+	}
+
+	// Test invalid path & tree
+	var invalidPath *merkletree.PoNs
+	assert.NotNil(t, invalidPath.Validate(tree))
+	var invalidTree1 *merkletree.Tree
+	assert.NotNil(t, (&merkletree.PoNs{}).Validate(invalidTree1))
+}
+
+// Leaf metadata, excluded from hashing & preserved by clone and marshal
+func TestLeaf_Meta(t *testing.T) {
+	_, root1, err := MockLeaves.Clone().BuildTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Build tree with metadata
+	leaves := MockLeaves.Clone()
+	(*leaves)[0].Meta = map[string][]byte{
+		"id":     []byte("0001"),
+		"source": []byte("s3://bucket/key"),
+		"empty":  {},
+	}
+	_, root2, err := leaves.BuildTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root1.Hash, root2.Hash)
+
+	// Clone
+	clone := (*leaves)[0].Clone()
+	assert.Equal(t, (*leaves)[0].Meta, clone.Meta)
+	clone.Meta["id"][0] = 'x'
+	assert.Equal(t, []byte("0001"), (*leaves)[0].Meta["id"])
+
+	// Marshal & unmarshal
+	data, err := root2.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root3 merkletree.Root
+	if err := json.Unmarshal(data, &root3); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, (*leaves)[0].Meta, root3.Left.Left.Left.Left.Meta)
+}
+
+// Build tree with parent links, extract proof by graph traversal
+func TestLeaves_BuildTree_WithParentLinks(t *testing.T) {
+	h := GetCustomHashFunc()
+	leaves := MockLeaves.Clone()
+	tree, root, err := leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithParentLinks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, root.Parent)
+	assert.Nil(t, root.Sibling())
+
+	for index := range *leaves {
+		leaf := &(*leaves)[index]
+
+		// Walk from leaf to root, collect siblings
+		var siblings []merkletree.Hash
+		node := leaf
+		for node.Parent != nil {
+			siblings = append(siblings, node.Sibling().Hash)
+			node = node.Parent
+		}
+		assert.True(t, root == node, "index=%d", index)
+
+		proof, err := tree.GetProof(uint64(index))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, proof.Siblings, siblings, "index=%d", index)
+	}
+
+	// Test without parent links
+	_, root, err = MockLeaves.Clone().BuildTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, root.Left.Parent)
+	assert.Nil(t, root.Left.Sibling())
+
+	// Root with parent links can be marshaled
+	_, root, err = MockLeaves.Clone().BuildTree(merkletree.WithParentLinks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = root.Marshal()
+	assert.Nil(t, err)
+}
+
+// Sort leaves with deterministic tie-breaking
+func TestLeaves_SortStable(t *testing.T) {
+	leaves := merkletree.Leaves{
+		{Hash: badHash, Payload: []byte("b")},
+		{Hash: goodHash, Payload: []byte("z"), Meta: map[string][]byte{"id": []byte("1")}},
+		{Hash: badHash, Payload: []byte("a")},
+		{Hash: goodHash, Payload: []byte("z"), Meta: map[string][]byte{"id": []byte("2")}},
+		{Hash: goodHash, Payload: []byte("y")},
+	}
+
+	leaves.SortStable()
+	t.Log("Leaves(Sorted)=\n", leaves)
+
+	assert.Equal(t, []byte("a"), leaves[0].Payload)
+	assert.Equal(t, []byte("b"), leaves[1].Payload)
+	assert.Equal(t, []byte("y"), leaves[2].Payload)
+	assert.Equal(t, []byte("1"), leaves[3].Meta["id"])
+	assert.Equal(t, []byte("2"), leaves[4].Meta["id"])
+
+	var invalidLeaves *merkletree.Leaves
+	invalidLeaves.SortStable()
+	assert.Nil(t, invalidLeaves)
+}
+
+// Build tree of a single leaf whose root is the leaf itself
+func TestLeaves_BuildTree_SingleLeafRoot(t *testing.T) {
+	h := GetCustomHashFunc()
+	opt := merkletree.WithSingleLeafRoot(true)
+
+	leaves := merkletree.Leaves{{Payload: []byte("你好")}}
+	tree, root, err := leaves.BuildTree(merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, goodHash, root.Hash)
+	assert.Equal(t, 0, root.Height)
+	assert.Equal(t, 1, leaves.Length())
+	assert.Equal(t, &merkletree.Tree{{goodHash}}, tree)
+
+	proof, err := tree.GetProof(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, proof.Path)
+	result, err := proof.Verify(root.Hash, h, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Default pairs the leaf with its duplicate
+	single := merkletree.Leaves{{Payload: []byte("你好")}}
+	_, paired, err := single.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, goodHash, paired.Hash)
+
+	// Builder, window & fixed-size tree
+	b := merkletree.NewBuilder(merkletree.WithHashFunc(h), opt)
+	if err = b.AddHash(goodHash); err != nil {
+		t.Fatal(err)
+	}
+	builderRoot, err := b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, goodHash, builderRoot)
+
+	w, err := merkletree.NewWindow(4, merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.AppendHash(goodHash); err != nil {
+		t.Fatal(err)
+	}
+	windowRoot, err := w.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, goodHash, windowRoot)
+	assert.Equal(t, tree, w.Tree())
+
+	single = merkletree.Leaves{{Payload: []byte("你好")}}
+	tree32, err := single.BuildTree32(merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree, tree32.Tree())
+
+	// Trees of more leaves are not affected
+	for _, n := range []int{2, 3, 5} {
+		_, root1, err := mockLeaves(n).BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, root2, err := mockLeaves(n).BuildTree(merkletree.WithHashFunc(h), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, root1.Hash, root2.Hash)
+	}
+
+	// Combine shards whose last one has a single leaf
+	all := mockLeaves(5)
+	_, expected, err := all.Clone().BuildTree(merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, last := (*all)[:4], (*all)[4:]
+	shard1, err := first.Summarize(merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shard2, err := last.Summarize(merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	combined, err := merkletree.CombineShards([]merkletree.ShardSummary{*shard1, *shard2}, merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected.Hash, combined)
+}
+
+// Exclude the synthetic duplicate of the last leaf
+func TestLeaves_BuildTree_Synthetic(t *testing.T) {
+	h := GetCustomHashFunc()
+	leaves := MockLeaves.Clone()
+	count := leaves.Length()
+	assert.Equal(t, 1, count%2)
+
+	tree, root, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, count+1, leaves.Length())
+	assert.Equal(t, count, leaves.Count())
+	assert.True(t, leaves.LastLeaf().Synthetic)
+	assert.False(t, (*leaves)[count-1].Synthetic)
+	assert.Equal(t, uint64(count), root.LeafCount())
+
+	opt := merkletree.WithLeafCount(uint64(count))
+
+	// Synthetic position is rejected
+	_, err = tree.PathForLeaf(uint64(count), opt)
+	assert.IsType(t, &merkletree.IndexError{}, err)
+	t.Log("leaf is synthetic, get path failed as expected, err=", err)
+
+	_, err = tree.GetProof(uint64(count), opt)
+	assert.NotNil(t, err)
+
+	// Still allowed without leaf count
+	_, err = tree.GetProof(uint64(count))
+	assert.Nil(t, err)
+
+	proof, err := tree.GetProof(uint64(count-1), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := proof.Verify(root.Hash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Enumeration excludes the synthetic duplicate
+	lastHash := (*leaves)[count-1].Hash
+	assert.Equal(t, []uint64{uint64(count - 1), uint64(count)}, tree.LeafIndexes(lastHash))
+	assert.Equal(t, []uint64{uint64(count - 1)}, tree.LeafIndexes(lastHash, opt))
+
+	// Test leaf count mismatching the tree
+	_, err = tree.PathForLeaf(0, merkletree.WithLeafCount(uint64(count-2)))
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	assert.Nil(t, tree.LeafIndexes(lastHash, merkletree.WithLeafCount(uint64(count+2))))
+
+	// Test other APIs with leaf count
+	report, err := tree.AuditSample(count+1, h, nil, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, count, len(report.Sampled))
+	assert.True(t, report.OK())
+
+	tree32, err := MockLeaves.Clone().BuildTree32(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tree32.GetProof(uint64(count), opt)
+	assert.IsType(t, &merkletree.IndexError{}, err)
+	_, err = tree32.GetProof(uint64(count))
+	assert.Nil(t, err)
+
+	_, err = tree.GetKaryProof(uint64(count), 2, opt)
+	assert.IsType(t, &merkletree.IndexError{}, err)
+	_, err = tree.GetKaryProof(uint64(count-1), 2, opt)
+	assert.Nil(t, err)
+
+	_, err = tree.RangeRoot(0, uint64(count+1), h, opt)
+	assert.IsType(t, &merkletree.IndexError{}, err)
+	rangeRoot, err := tree.RangeRoot(0, uint64(count), h, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rangeRoot)
+
+	// Enumeration excludes the synthetic duplicate
+	originals := leaves.Originals()
+	assert.Equal(t, count, len(originals))
+	for _, leaf := range originals {
+		assert.False(t, leaf.Synthetic)
+	}
+
+	summary, err := leaves.Summarize(merkletree.WithHashFunc(h), merkletree.WithSkipHash(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(count), summary.Size)
+	assert.Equal(t, root.Hash, []byte(summary.Root))
+
+	// Adding a leaf replaces the synthetic duplicate
+	leaves.Add(&merkletree.Leaf{Payload: []byte("Hello")})
+	assert.Equal(t, count+1, leaves.Length())
+	assert.Equal(t, count+1, leaves.Count())
+	_, root2, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MockLeaves.Clone()
+	expected.Add(&merkletree.Leaf{Payload: []byte("Hello")})
+	_, root3, err := expected.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root3.Hash, root2.Hash)
+
+	// Even leaves have no synthetic duplicate
+	even := mockLeaves(4)
+	_, root, err = even.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 4, even.Count())
+	assert.Equal(t, uint64(4), root.LeafCount())
+}
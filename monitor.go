@@ -0,0 +1,195 @@
+package merkletree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultMonitorInterval default interval between rounds of monitor
+const DefaultMonitorInterval = time.Minute
+
+// IntegrityError is reported by monitor when the tree is inconsistent
+type IntegrityError struct {
+	// Branches inconsistent with their children, found by verifying the
+	// whole tree
+	Branches PoNs
+
+	// Leaves whose proofs failed, found by audit sampling
+	Leaves []uint64
+}
+
+// Error returns error message
+func (e *IntegrityError) Error() string {
+	if len(e.Branches) > 0 {
+		return fmt.Sprintf("tree is inconsistent: %d branches mismatch their children, first at (%d,%d)",
+			len(e.Branches), e.Branches[0][0], e.Branches[0][1])
+	}
+
+	return fmt.Sprintf("tree is inconsistent: proofs of %d leaves failed, first of leaf %d", len(e.Leaves), e.Leaves[0])
+}
+
+// MonitorConfig config of monitor
+type MonitorConfig struct {
+	// Interval between rounds, DefaultMonitorInterval is used if it's zero
+	Interval time.Duration
+
+	// Fraction of leaves audited by sampling each round, the whole tree is
+	// verified if it's <= 0 or >= 1
+	Fraction float64
+
+	// Locker is held during each round if it's not nil, it should be the
+	// lock guarding writes to the tree
+	Locker sync.Locker
+
+	// Rand is used to sample leaves, a time-seeded one is used if it's nil
+	Rand *rand.Rand
+
+	// OnInconsistency is called with the first inconsistency, which is an
+	// *IntegrityError, or with the error failing the round. The monitor is
+	// stopped after.
+	OnInconsistency func(err error)
+}
+
+// Monitor re-validates a tree periodically in background, so bit-rot & bugs
+// of long-lived trees are detected early
+type Monitor struct {
+	tree   *Tree
+	h      IHashFunc
+	opt    []OptionFunc
+	opts   Options
+	config MonitorConfig
+
+	rounds uint64
+	err    error
+	mu     sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// StartMonitor starts monitor of tree in a goroutine, the first round runs
+// after the interval. The monitor is stopped by Stop, by the first
+// inconsistency, or when the context configured by WithContext is done.
+func (tree *Tree) StartMonitor(h IHashFunc, config MonitorConfig, opt ...OptionFunc) (*Monitor, error) {
+	if tree == nil || tree.Height() == 0 {
+		return nil, errors.New("tree is empty")
+	} else if config.Interval < 0 {
+		return nil, errors.New("invalid interval")
+	}
+
+	if config.Interval == 0 {
+		config.Interval = DefaultMonitorInterval
+	}
+	if config.Rand == nil {
+		config.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	m := &Monitor{
+		tree:   tree,
+		h:      h,
+		opt:    opt,
+		opts:   NewOptions(opt...),
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go m.run()
+
+	return m, nil
+}
+
+// Stop stops monitor & waits for the running round
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+}
+
+// Done returns a channel closed when monitor is stopped
+func (m *Monitor) Done() <-chan struct{} {
+	return m.done
+}
+
+// Rounds returns number of rounds completed
+func (m *Monitor) Rounds() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.rounds
+}
+
+// Err returns the error stopping monitor, nil if monitor is running or
+// stopped by Stop
+func (m *Monitor) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.err
+}
+
+// run runs rounds until monitor is stopped
+func (m *Monitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-m.opts.Context.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := m.check()
+
+		m.mu.Lock()
+		m.rounds++
+		m.err = err
+		m.mu.Unlock()
+
+		if err != nil {
+			if m.config.OnInconsistency != nil {
+				m.config.OnInconsistency(err)
+			}
+			return
+		}
+	}
+}
+
+// check runs a round, returns *IntegrityError if tree is inconsistent
+func (m *Monitor) check() error {
+	if m.config.Locker != nil {
+		m.config.Locker.Lock()
+		defer m.config.Locker.Unlock()
+	}
+
+	if m.config.Fraction <= 0 || m.config.Fraction >= 1 {
+		branches, err := m.tree.Verify(m.h, m.opt...)
+		if err != nil {
+			return err
+		} else if len(branches) > 0 {
+			return &IntegrityError{Branches: branches}
+		}
+		return nil
+	}
+
+	n := int(math.Ceil(m.config.Fraction * float64(m.tree.Width(0))))
+	report, err := m.tree.AuditSample(n, m.h, m.config.Rand, m.opt...)
+	if err != nil {
+		return err
+	} else if !report.OK() {
+		return &IntegrityError{Leaves: report.Failed}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package merkletree_test
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Monitor detects corrupted branch
+func TestTree_StartMonitor(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, fraction := range []float64{0, 0.5} {
+		tree, _, err := mockLeaves(16).BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var mu sync.Mutex
+		reported := make(chan error, 1)
+		m, err := tree.StartMonitor(h, merkletree.MonitorConfig{
+			Interval: time.Millisecond,
+			Fraction: fraction,
+			Locker:   &mu,
+			Rand:     rand.New(rand.NewSource(1)),
+			OnInconsistency: func(err error) {
+				reported <- err
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Wait for some good rounds
+		for m.Rounds() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Nil(t, m.Err())
+
+		// Corrupt a branch right below the root, which fails half of the leaves
+		mu.Lock()
+		(*tree)[tree.Y()-1][0] = badHash
+		mu.Unlock()
+
+		select {
+		case err = <-reported:
+		case <-time.After(5 * time.Second):
+			t.Fatal("inconsistency is not reported")
+		}
+		t.Log("tree corrupted, monitor reported as expected, err=", err)
+		assert.IsType(t, &merkletree.IntegrityError{}, err)
+		<-m.Done()
+		assert.Equal(t, err, m.Err())
+		m.Stop()
+	}
+}
+
+// Stop monitor
+func TestMonitor_Stop(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, _, err := mockLeaves(16).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := tree.StartMonitor(h, merkletree.MonitorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+	m.Stop()
+	assert.Nil(t, m.Err())
+
+	// Test context
+	ctx, cancel := context.WithCancel(context.Background())
+	m, err = tree.StartMonitor(h, merkletree.MonitorConfig{}, merkletree.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	<-m.Done()
+
+	// Test invalid config
+	_, err = tree.StartMonitor(h, merkletree.MonitorConfig{Interval: -1})
+	assert.NotNil(t, err)
+
+	var empty *merkletree.Tree
+	_, err = empty.StartMonitor(h, merkletree.MonitorConfig{})
+	assert.NotNil(t, err)
+}
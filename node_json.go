@@ -0,0 +1,101 @@
+package merkletree
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxNodeDepth is the max depth of a node graph accepted by Root.Marshal &
+// by JSON decoding of Node, a tree of 2^64 leaves is 65 levels deep
+var MaxNodeDepth = 128
+
+// UnmarshalJSON decode node, rejects JSON nested deeper than MaxNodeDepth
+// before decoding it
+func (node *Node) UnmarshalJSON(data []byte) error {
+	// One more level for the values of a node, e.g. a map
+	if depth := jsonDepth(data); depth > MaxNodeDepth+1 {
+		return fmt.Errorf("node depth %d exceeds max depth %d", depth, MaxNodeDepth)
+	}
+
+	type plainNode Node
+	return json.Unmarshal(data, (*plainNode)(node))
+}
+
+// checkGraph returns error if graph of node has a cycle or is deeper than
+// maxDepth. Shared subtrees, e.g. a node paired with itself, are allowed.
+func (node *Node) checkGraph(maxDepth int) error {
+	_, err := node.graphDepth(make(map[*Node]int), make(map[*Node]bool), 1, maxDepth)
+	return err
+}
+
+// graphDepth returns depth of graph of node. depths are the depths of
+// checked nodes, visiting are the nodes on the current path.
+func (node *Node) graphDepth(depths map[*Node]int, visiting map[*Node]bool, level int, maxDepth int) (int, error) {
+	if node == nil {
+		return 0, nil
+	} else if visiting[node] {
+		return 0, errors.New("node graph has a cycle")
+	} else if level > maxDepth {
+		return 0, fmt.Errorf("node depth exceeds max depth %d", maxDepth)
+	}
+
+	if depth, ok := depths[node]; ok {
+		if level+depth-1 > maxDepth {
+			return 0, fmt.Errorf("node depth exceeds max depth %d", maxDepth)
+		}
+		return depth, nil
+	}
+
+	visiting[node] = true
+	defer delete(visiting, node)
+
+	depth := 0
+	for _, child := range node.children() {
+		childDepth, err := child.graphDepth(depths, visiting, level+1, maxDepth)
+		if err != nil {
+			return 0, err
+		}
+
+		if childDepth > depth {
+			depth = childDepth
+		}
+	}
+	depths[node] = depth + 1
+
+	return depth + 1, nil
+}
+
+// jsonDepth returns max nesting depth of objects & arrays in JSON
+func jsonDepth(data []byte) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+
+	return maxDepth
+}
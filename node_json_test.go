@@ -0,0 +1,68 @@
+package merkletree_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Marshal node graph with cycle
+func TestRoot_Marshal_Cycle(t *testing.T) {
+	root := &merkletree.Root{Hash: goodHash}
+	child := &merkletree.Node{Hash: badHash, Left: root}
+	root.Left = child
+
+	_, err := root.Marshal()
+	if err != nil {
+		t.Log("node graph has a cycle, marshal failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	// Test shared subtree, which is not a cycle
+	leaf := &merkletree.Leaf{Hash: goodHash}
+	shared := &merkletree.Root{Hash: badHash, Left: leaf, Right: leaf}
+	_, err = shared.Marshal()
+	assert.Nil(t, err)
+}
+
+// Marshal node graph deeper than max depth
+func TestRoot_Marshal_Depth(t *testing.T) {
+	root := &merkletree.Root{Hash: goodHash}
+	node := root
+	for i := 0; i < merkletree.MaxNodeDepth; i++ {
+		node.Left = &merkletree.Node{Hash: goodHash}
+		node = node.Left
+	}
+
+	_, err := root.Marshal()
+	if err != nil {
+		t.Log("node graph is too deep, marshal failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	// Test max depth
+	_, err = root.Left.Marshal()
+	assert.Nil(t, err)
+}
+
+// Unmarshal node JSON deeper than max depth
+func TestNode_UnmarshalJSON_Depth(t *testing.T) {
+	depth := merkletree.MaxNodeDepth + 10
+	data := strings.Repeat(`{"Left":`, depth) + "null" + strings.Repeat("}", depth)
+
+	var root merkletree.Root
+	err := json.Unmarshal([]byte(data), &root)
+	if err != nil {
+		t.Log("node JSON is too deep, unmarshal failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	// Test strings with brackets are not counted
+	data = `{"Unknown":"\"` + strings.Repeat("{", depth) + `"}`
+	assert.Nil(t, json.Unmarshal([]byte(data), &root))
+	data = `{"Height":1,"Left":{"Hash":"3q2+7w=="}}`
+	assert.Nil(t, json.Unmarshal([]byte(data), &root))
+}
@@ -0,0 +1,99 @@
+package merkletree
+
+import (
+	"fmt"
+)
+
+// OddRule is how the last node of a level of odd width is hashed
+type OddRule int
+
+const (
+	// OddDuplicate pairs the last node with itself, an odd number of leaves
+	// is made even by the synthetic duplicate of the last leaf
+	OddDuplicate OddRule = iota
+
+	// OddPromote promotes the last node to the next level without hashing,
+	// as in RFC 6962. A merkle path skips the levels the node is promoted.
+	OddPromote
+)
+
+// checkOddRule returns error if odd rule of options is unknown
+func (opts *Options) checkOddRule() error {
+	if opts.OddRule != OddDuplicate && opts.OddRule != OddPromote {
+		return fmt.Errorf("invalid odd rule %d", opts.OddRule)
+	}
+
+	return nil
+}
+
+// checkDuplicate returns error if odd rule of options is not OddDuplicate
+func (opts *Options) checkDuplicate() error {
+	if opts.OddRule != OddDuplicate {
+		return fmt.Errorf("odd rule %d is not supported, only duplicate is", opts.OddRule)
+	}
+
+	return nil
+}
+
+// buildPromoted build tree whose last node of a level of odd width is
+// promoted to the next level, the tree holds its hash on both levels
+func (obj *Leaves) buildPromoted(opts Options) (*Tree, *Root, error) {
+	tree, err := obj.initTree()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := make([]*Node, obj.Length())
+	for i := range *obj {
+		nodes[i] = &(*obj)[i]
+	}
+
+	for len(nodes) > 1 {
+		if err := opts.Context.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		branches := make([]*Node, 0, (len(nodes)+1)/2)
+		hashSet := make([]Hash, 0, cap(branches))
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				branches = append(branches, nodes[i])
+				hashSet = append(hashSet, nodes[i].Hash)
+				continue
+			}
+
+			digest, err := hashPair(opts.HashFunc, opts.NodeTag, nodes[i].Hash, nodes[i+1].Hash, opts.SortedPairHashing)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			branches = append(branches, &Node{
+				Height: nodes[i].Height + 1,
+				Hash:   digest,
+				Left:   nodes[i],
+				Right:  nodes[i+1],
+			})
+			hashSet = append(hashSet, digest)
+		}
+
+		*tree = append(*tree, hashSet)
+		nodes = branches
+	}
+
+	root := nodes[0]
+	if opts.ParentLinks {
+		root.linkParents()
+	}
+
+	return tree, root, nil
+}
+
+// promoted returns position of the node after it's promoted over the levels
+// where it has no brother, up to the root
+func (tree *Tree) promoted(pon PoN) PoN {
+	for pon[0] < tree.Y() && pon[1]^1 > tree.X(pon[0]) {
+		pon = pon.GetParent()
+	}
+
+	return pon
+}
@@ -0,0 +1,195 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build tree promoting the last node of odd levels, prove all leaves
+func TestWithOddRule_Promote(t *testing.T) {
+	h := GetCustomHashFunc()
+	opts := []merkletree.OptionFunc{
+		merkletree.WithHashFunc(h),
+		merkletree.WithOddRule(merkletree.OddPromote),
+	}
+
+	for size := 1; size <= 33; size++ {
+		tree, root, err := mockLeaves(size).BuildTree(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, uint64(size), tree.Width(0), "size=%d", size)
+
+		// Test builder
+		builder := merkletree.NewBuilder(opts...)
+		if err := builder.AddLeaves(mockLeaves(size)); err != nil {
+			t.Fatal(err)
+		}
+		rootHash, err := builder.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, root.Hash, rootHash, "size=%d", size)
+
+		// Test proofs
+		for index := uint64(0); index < uint64(size); index++ {
+			merklePath, err := tree.PathForLeaf(index, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.NoError(t, merklePath.Validate(tree, opts...), "size=%d index=%d", size, index)
+
+			proof, err := tree.GetProof(index, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := proof.Verify(root.Hash, h, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, result, "size=%d index=%d", size, index)
+
+			transcript, err := tree.Explain(&merklePath, proof.Leaf, h, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, transcript.Result, "size=%d index=%d", size, index)
+
+			// Test tampered index
+			for _, tampered := range []uint64{index ^ 1, index + 1, index + 2} {
+				if tampered == index {
+					continue
+				}
+				proof.Index = tampered
+				result, err := proof.Verify(root.Hash, h, opts...)
+				assert.False(t, result && err == nil, "size=%d index=%d tampered=%d", size, index, tampered)
+			}
+		}
+
+		// Test repair
+		pons, err := tree.Verify(h, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, pons, "size=%d", size)
+	}
+}
+
+// Promote the last node of odd levels in shards, ranges & repair
+func TestWithOddRule_Promote_Others(t *testing.T) {
+	h := GetCustomHashFunc()
+	opts := []merkletree.OptionFunc{
+		merkletree.WithHashFunc(h),
+		merkletree.WithOddRule(merkletree.OddPromote),
+	}
+
+	// Test shards
+	for size := 1; size <= 21; size++ {
+		_, root, err := mockLeaves(size).BuildTree(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaves := *mockLeaves(size)
+		var shards []merkletree.ShardSummary
+		for i := 0; i < size; i += 4 {
+			end := i + 4
+			if end > size {
+				end = size
+			}
+			shard := leaves[i:end]
+			summary, err := shard.Summarize(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shards = append(shards, *summary)
+		}
+
+		rootHash, err := merkletree.CombineShards(shards, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, root.Hash, rootHash, "size=%d", size)
+	}
+
+	// Test range
+	tree, root, err := mockLeaves(13).BuildTree(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rangeRoot, err := tree.RangeRoot(8, 12, h, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merklePath, err := tree.RangeProof(8, 12, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := tree.ProveAgainstRoot(&merklePath, rangeRoot, root.Hash, h, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Test path starting above the leaf level, which is only valid for the
+	// last leaf promoted to the level
+	five, _, err := mockLeaves(5).BuildTree(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, (&merkletree.PoNs{{2, 0}}).Validate(five, opts...))
+	assert.NotNil(t, (&merkletree.PoNs{{1, 1}, {2, 1}}).Validate(five, opts...))
+	assert.NotNil(t, (&merkletree.PoNs{{2, 0}}).Validate(five))
+
+	// Test repair of a promoted node
+	clone := tree.Clone()
+	(*clone)[1][6] = badHash
+	pons, err := clone.Repair(h, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, merkletree.PoNs{{1, 6}}, pons)
+	assert.Equal(t, *tree, *clone)
+}
+
+// Reject odd rules not supported
+func TestWithOddRule_Unsupported(t *testing.T) {
+	h := GetCustomHashFunc()
+	promote := merkletree.WithOddRule(merkletree.OddPromote)
+	opt := merkletree.WithHashFunc(h)
+
+	_, _, err := mockLeaves(5).BuildTree(opt, merkletree.WithOddRule(merkletree.OddRule(-1)))
+	assert.NotNil(t, err)
+	t.Log("odd rule is invalid, build tree failed as expected, err=", err)
+
+	_, _, err = mockLeaves(5).BuildTree(opt, promote, merkletree.WithArity(4))
+	assert.NotNil(t, err)
+
+	_, err = mockLeaves(5).BuildTree32(opt, promote)
+	assert.NotNil(t, err)
+
+	_, err = merkletree.NewWindow(4, opt, promote)
+	assert.NotNil(t, err)
+
+	tree, root, err := mockLeaves(5).BuildTree(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = root.Update(1, merkletree.Leaf{Hash: goodHash}, h, promote)
+	assert.NotNil(t, err)
+
+	_, err = tree.NewProofToken(1, merkletree.HashSHA256, promote)
+	assert.NotNil(t, err)
+
+	_, err = tree.NewProofToken(1, merkletree.HashSHA256, merkletree.WithDomainTags(nil, []byte{1}))
+	assert.NotNil(t, err)
+
+	kary, err := tree.GetKaryProof(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = kary.Verify(root.Hash, h, promote)
+	assert.NotNil(t, err)
+}
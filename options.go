@@ -12,6 +12,75 @@ type Options struct {
 	// SkipHash switch
 	SkipHash bool
 
+	// SortedPairHashing switch, if true the children of a branch are hashed
+	// in byte-sorted order instead of by position
+	SortedPairHashing bool
+
+	// MemoryBudget in bytes, build is rejected if the estimated memory
+	// exceeds it. Zero means no limit
+	MemoryBudget uint64
+
+	// Workers is the number of workers of concurrent builds, zero means the
+	// number of CPUs
+	Workers int
+
+	// DiscardPayload switch, if true payloads of leaves are released as
+	// soon as they are hashed, and leaves of digest only are not hashed
+	// again when they are rebuilt
+	DiscardPayload bool
+
+	// PayloadStore stores payloads of leaves after hashing, the leaves keep
+	// digests only
+	PayloadStore PayloadStore
+
+	// ParentLinks switch, if true parents of all nodes are linked, the
+	// leaves of the graph are the elements of the leaves
+	ParentLinks bool
+
+	// Compression is the name of a registered compressor, which compresses
+	// data of versioned envelopes. Empty means no compression
+	Compression string
+
+	// Tracer traces operations, nil means no tracing
+	Tracer Tracer
+
+	// DecodeLimits limits of decoding artifacts
+	DecodeLimits DecodeLimits
+
+	// AllowEmpty switch, if true a tree without leaves is built with the
+	// empty hash as root instead of returning error
+	AllowEmpty bool
+
+	// EmptyHash is the root of a tree without leaves, nil means the hash of
+	// empty message
+	EmptyHash []byte
+
+	// SingleLeafRoot switch, if true the root of a tree of a single leaf is
+	// the leaf itself, as in Certificate Transparency & Bitcoin. Otherwise
+	// the leaf is paired with its duplicate
+	SingleLeafRoot bool
+
+	// LeafCount is the number of leaves excluding the synthetic duplicate,
+	// which is rejected by proof APIs if it's set. Zero means unknown
+	LeafCount uint64
+
+	// Arity is the number of children of a branch, zero means 2, at most
+	// MaxArity. Trees of arity > 2 are proved by KaryProof, other features
+	// are binary only
+	Arity int
+
+	// OddRule is how the last node of a level of odd width is hashed, zero
+	// means OddDuplicate
+	OddRule OddRule
+
+	// LeafTag is the domain tag prefixed to payloads of leaves before
+	// hashing, nil means no tag
+	LeafTag []byte
+
+	// NodeTag is the domain tag prefixed to children of branches before
+	// hashing, nil means no tag
+	NodeTag []byte
+
 	// Options for implementations of the interface can be stored in a context
 	Context context.Context
 }
@@ -46,3 +115,128 @@ func WithSkipHash(skipHash bool) OptionFunc {
 		o.SkipHash = skipHash
 	}
 }
+
+// WithSortedPairHashing option to configure sorted pair hashing
+func WithSortedPairHashing(sorted bool) OptionFunc {
+	return func(o *Options) {
+		o.SortedPairHashing = sorted
+	}
+}
+
+// WithContext option to configure context, the context is checked for
+// cancellation while building
+func WithContext(ctx context.Context) OptionFunc {
+	return func(o *Options) {
+		o.Context = ctx
+	}
+}
+
+// WithMemoryBudget option to configure memory budget in bytes
+func WithMemoryBudget(budget uint64) OptionFunc {
+	return func(o *Options) {
+		o.MemoryBudget = budget
+	}
+}
+
+// WithWorkers option to configure number of workers
+func WithWorkers(workers int) OptionFunc {
+	return func(o *Options) {
+		o.Workers = workers
+	}
+}
+
+// WithPayloadStore option to configure payload store
+func WithPayloadStore(store PayloadStore) OptionFunc {
+	return func(o *Options) {
+		o.PayloadStore = store
+	}
+}
+
+// WithDiscardPayload option to configure discard payload
+func WithDiscardPayload(discard bool) OptionFunc {
+	return func(o *Options) {
+		o.DiscardPayload = discard
+	}
+}
+
+// WithParentLinks option to configure parent links
+func WithParentLinks(link bool) OptionFunc {
+	return func(o *Options) {
+		o.ParentLinks = link
+	}
+}
+
+// WithCompression option to configure compression of versioned envelopes
+func WithCompression(name string) OptionFunc {
+	return func(o *Options) {
+		o.Compression = name
+	}
+}
+
+// WithTracer option to configure tracer
+func WithTracer(tracer Tracer) OptionFunc {
+	return func(o *Options) {
+		o.Tracer = tracer
+	}
+}
+
+// WithDecodeLimits option to configure limits of decoding artifacts
+func WithDecodeLimits(limits DecodeLimits) OptionFunc {
+	return func(o *Options) {
+		o.DecodeLimits = limits
+	}
+}
+
+// WithAllowEmpty option to configure allow empty
+func WithAllowEmpty(allow bool) OptionFunc {
+	return func(o *Options) {
+		o.AllowEmpty = allow
+	}
+}
+
+// WithEmptyHash option to configure root of a tree without leaves, which
+// is used if AllowEmpty is set
+func WithEmptyHash(hash []byte) OptionFunc {
+	return func(o *Options) {
+		o.EmptyHash = hash
+	}
+}
+
+// WithSingleLeafRoot option to configure single leaf root
+func WithSingleLeafRoot(single bool) OptionFunc {
+	return func(o *Options) {
+		o.SingleLeafRoot = single
+	}
+}
+
+// WithArity option to configure arity of tree
+func WithArity(k int) OptionFunc {
+	return func(o *Options) {
+		o.Arity = k
+	}
+}
+
+// WithLeafCount option to configure number of leaves excluding the
+// synthetic duplicate, e.g. Leaves.Count or Root.LeafCount
+func WithLeafCount(count uint64) OptionFunc {
+	return func(o *Options) {
+		o.LeafCount = count
+	}
+}
+
+// WithOddRule option to configure how the last node of a level of odd width
+// is hashed
+func WithOddRule(rule OddRule) OptionFunc {
+	return func(o *Options) {
+		o.OddRule = rule
+	}
+}
+
+// WithDomainTags option to configure domain tags of leaves & branches, e.g.
+// 0x00 & 0x01 of RFC 6962, so a leaf can not be taken as a branch
+func WithDomainTags(leafTag []byte, nodeTag []byte) OptionFunc {
+	return func(o *Options) {
+		o.LeafTag = leafTag
+		o.NodeTag = nodeTag
+	}
+}
@@ -0,0 +1,94 @@
+package merkletree
+
+import (
+	"errors"
+)
+
+// Update returns a new version of the tree with the leaf at index replaced,
+// the original root is not modified. Only the nodes on the path from the
+// leaf to the root are copied, all other subtrees are shared between the
+// versions, so keeping many versions costs O(changes * log n). The hash of
+// leaf must be set & of the digest size of h. The synthetic duplicate of the
+// last leaf is updated with it, & can not be updated by itself.
+func (node *Root) Update(index uint64, leaf Leaf, h IHashFunc, opt ...OptionFunc) (*Root, error) {
+	if node == nil {
+		return nil, errors.New("root is empty")
+	} else if leaf.Hash == nil {
+		return nil, errors.New("leaf hash is empty")
+	} else if node.Height < 64 && index>>uint(node.Height) != 0 {
+		return nil, &IndexError{Index: index, Count: 1 << uint(node.Height)}
+	}
+
+	size, err := hashSize(h)
+	if err != nil {
+		return nil, err
+	} else if err := checkDigest("leaf", leaf.Hash, size); err != nil {
+		return nil, err
+	}
+
+	opts := NewOptions(append(opt, WithHashFunc(h))...)
+	if err := opts.checkDuplicate(); err != nil {
+		return nil, err
+	}
+
+	return node.update(index, &leaf, opts)
+}
+
+// update returns a copy of node with the leaf at index replaced
+func (node *Node) update(index uint64, leaf *Leaf, opts Options) (*Node, error) {
+	if node.Height == 0 {
+		leaf.Height = 0
+		leaf.Synthetic = false
+		return leaf, nil
+	}
+
+	if node.Left == nil || node.Right == nil {
+		return nil, errors.New("invalid node")
+	}
+
+	clone := *node
+	right := index>>uint(node.Height-1)&1 == 1
+
+	switch {
+	case node.Left == node.Right && right:
+		// The node is paired with itself, there's no node at index
+		return nil, errors.New("invalid index")
+	case node.Height == 1 && node.Right.Synthetic && right:
+		return nil, errors.New("invalid index: leaf is synthetic")
+	case node.Height == 1 && node.Right.Synthetic:
+		// The synthetic duplicate follows the last leaf
+		child, err := node.Left.update(index, leaf, opts)
+		if err != nil {
+			return nil, err
+		}
+		duplicate := *child
+		duplicate.Synthetic = true
+		clone.Left, clone.Right = child, &duplicate
+	case node.Left == node.Right:
+		child, err := node.Left.update(index, leaf, opts)
+		if err != nil {
+			return nil, err
+		}
+		clone.Left, clone.Right = child, child
+	case right:
+		child, err := node.Right.update(index, leaf, opts)
+		if err != nil {
+			return nil, err
+		}
+		clone.Right = child
+	default:
+		child, err := node.Left.update(index, leaf, opts)
+		if err != nil {
+			return nil, err
+		}
+		clone.Left = child
+	}
+
+	digest, err := hashPair(opts.HashFunc, opts.NodeTag, clone.Left.Hash, clone.Right.Hash, opts.SortedPairHashing)
+	if err != nil {
+		return nil, err
+	}
+	clone.Hash = digest
+
+	return &clone, nil
+}
@@ -0,0 +1,126 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Update leaf, returns new version sharing unchanged subtrees
+func TestRoot_Update(t *testing.T) {
+	h := GetCustomHashFunc()
+	_, root1, err := mockLeaves(16).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash1 := append([]byte{}, root1.Hash...)
+
+	// Update leaf 3
+	leaf := merkletree.Leaf{Payload: []byte("Hello")}
+	if leaf.Hash, err = h.Hash(leaf.Payload); err != nil {
+		t.Fatal(err)
+	}
+	root2, err := root1.Update(3, leaf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Compare with a tree rebuilt from the updated leaves
+	leaves := mockLeaves(16)
+	(*leaves)[3] = merkletree.Leaf{Payload: []byte("Hello")}
+	_, expected, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected.Hash, root2.Hash)
+
+	// The original version is unchanged, the right half is shared
+	assert.Equal(t, rootHash1, root1.Hash)
+	assert.True(t, root1.Right == root2.Right)
+	assert.False(t, root1.Left == root2.Left)
+	assert.True(t, root1.Left.Right == root2.Left.Right)
+
+	// Test invalid index
+	_, err = root1.Update(16, leaf, h)
+	if err != nil {
+		t.Log("index is out of range, update failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test leaf without hash
+	_, err = root1.Update(0, merkletree.Leaf{}, h)
+	assert.NotNil(t, err)
+
+	// Test invalid root
+	var invalidRoot *merkletree.Root
+	_, err = invalidRoot.Update(0, leaf, h)
+	assert.NotNil(t, err)
+}
+
+// Update leaf of tree with nodes paired with themselves
+func TestRoot_Update_Unbalanced(t *testing.T) {
+	h := GetCustomHashFunc()
+	_, root1, err := mockLeaves(10).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf := merkletree.Leaf{Payload: []byte("Hello")}
+	if leaf.Hash, err = h.Hash(leaf.Payload); err != nil {
+		t.Fatal(err)
+	}
+	root2, err := root1.Update(9, leaf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaves := mockLeaves(10)
+	(*leaves)[9] = merkletree.Leaf{Payload: []byte("Hello")}
+	_, expected, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected.Hash, root2.Hash)
+
+	// Test index of node paired with itself
+	_, err = root1.Update(12, leaf, h)
+	if err != nil {
+		t.Log("no node at index, update failed as expected")
+	}
+	assert.NotNil(t, err)
+}
+
+// Update the last leaf of odd leaves, the synthetic duplicate follows it
+func TestRoot_Update_Synthetic(t *testing.T) {
+	h := GetCustomHashFunc()
+	_, root1, err := mockLeaves(5).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf := merkletree.Leaf{Payload: []byte("Hello")}
+	if leaf.Hash, err = h.Hash(leaf.Payload); err != nil {
+		t.Fatal(err)
+	}
+	root2, err := root1.Update(4, leaf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaves := mockLeaves(5)
+	(*leaves)[4] = merkletree.Leaf{Payload: []byte("Hello")}
+	_, expected, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected.Hash, root2.Hash)
+	assert.Equal(t, uint64(5), root2.LeafCount())
+
+	// Test synthetic duplicate
+	_, err = root1.Update(5, leaf, h)
+	if err != nil {
+		t.Log("leaf is synthetic, update failed as expected")
+	}
+	assert.NotNil(t, err)
+}
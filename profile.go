@@ -0,0 +1,138 @@
+package merkletree
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Profile is a bundle of options matching a common ecosystem, so the hash
+// function, pair ordering, odd rule & domain tags are always set
+// consistently
+type Profile struct {
+	// Name of the profile
+	Name string
+
+	// HashFunc used for leaves & branches
+	HashFunc IHashFunc
+
+	// SortedPairHashing switch
+	SortedPairHashing bool
+
+	// SingleLeafRoot switch, if true the root of a single leaf is the leaf
+	SingleLeafRoot bool
+
+	// OddRule is how the last node of a level of odd width is hashed
+	OddRule OddRule
+
+	// LeafTag & NodeTag are the domain tags of leaves & branches
+	LeafTag []byte
+	NodeTag []byte
+}
+
+// ProfileBitcoin is the profile of Bitcoin transaction merkle trees:
+// SHA-256d, children are hashed by position, the root of a block of a
+// single transaction is its txid
+var ProfileBitcoin = &Profile{
+	Name:           "bitcoin",
+	HashFunc:       &DoubleHashFunc{Provider: sha256.New},
+	SingleLeafRoot: true,
+}
+
+// ProfileCT is the profile of Certificate Transparency logs of RFC 6962:
+// SHA-256, leaves & branches are tagged by 0x00 & 0x01, the last node of a
+// level of odd width is promoted
+var ProfileCT = &Profile{
+	Name:           "ct",
+	HashFunc:       &HashFunc{Provider: sha256.New},
+	SingleLeafRoot: true,
+	OddRule:        OddPromote,
+	LeafTag:        []byte{0x00},
+	NodeTag:        []byte{0x01},
+}
+
+var (
+	// profiles registered by name
+	profiles   = make(map[string]*Profile)
+	profilesMu sync.RWMutex
+)
+
+func init() {
+	RegisterProfile(ProfileBitcoin)
+	RegisterProfile(ProfileCT)
+}
+
+// WithProfile option to configure all options of profile
+func WithProfile(profile *Profile) OptionFunc {
+	return func(o *Options) {
+		if profile == nil {
+			return
+		}
+
+		o.HashFunc = profile.HashFunc
+		o.SortedPairHashing = profile.SortedPairHashing
+		o.SingleLeafRoot = profile.SingleLeafRoot
+		o.OddRule = profile.OddRule
+		o.LeafTag = profile.LeafTag
+		o.NodeTag = profile.NodeTag
+	}
+}
+
+// WithProfileName option to configure all options of the profile registered
+// by name. If the name is unknown, the hash function returns the error of
+// LookupProfile, so building or verifying with the options fails.
+func WithProfileName(name string) OptionFunc {
+	profile, err := LookupProfile(name)
+	if err != nil {
+		return WithHashFunc(errHashFunc{err: err})
+	}
+
+	return WithProfile(profile)
+}
+
+// errHashFunc hash function returning err, for options which can not be
+// configured
+type errHashFunc struct {
+	err error
+}
+
+// Hash returns the error
+func (h errHashFunc) Hash([]byte) ([]byte, error) {
+	return nil, h.err
+}
+
+// RegisterProfile register profile by its name
+func RegisterProfile(profile *Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	profiles[profile.Name] = profile
+}
+
+// LookupProfile returns profile registered by name
+func LookupProfile(name string) (*Profile, error) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile '%s'", name)
+	}
+
+	return profile, nil
+}
+
+// ProfileNames returns names of registered profiles in sorted order
+func ProfileNames() []string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
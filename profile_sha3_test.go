@@ -0,0 +1,73 @@
+//go:build !merkletree_sha256
+// +build !merkletree_sha256
+
+package merkletree_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build tree with ethereum profile
+func TestWithProfile_Ethereum(t *testing.T) {
+	_, root1, err := MockLeaves.Clone().BuildTree(merkletree.WithProfile(merkletree.ProfileEthereum))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, root2, err := MockLeaves.Clone().BuildTree(merkletree.WithSortedPairHashing(true), merkletree.WithOddRule(merkletree.OddPromote))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root2.Hash, root1.Hash)
+
+	profile, err := merkletree.LookupProfile("ethereum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, merkletree.ProfileEthereum == profile)
+}
+
+// Build trees of the leaves "a", "b", ... with ethereum profile. The roots
+// are computed by the layer algorithm of merkletreejs with hashLeaves &
+// sortPairs, where the last node of an odd layer is carried up.
+func TestWithProfile_Ethereum_Vectors(t *testing.T) {
+	roots := []string{
+		"3ac225168df54212a25c1c01fd35bebfea408fdac2e31ddd6f80a4bbf9a5f1cb",
+		"805b21d846b189efaeb0377d6bb0d201b3872a363e607c25088f025b0c6ae1f8",
+		"5842148bc6ebeb52af882a317c765fccd3ae80589b21a9b8cbf21abb630e46a7",
+		"68203f90e9d07dc5859259d7536e87a6ba9d345f2552b5b9de2999ddce9ce1bf",
+		"1dd0d2a6ae466d665cb26e1a31f07c57ae5df7d2bc559cd5826d417be9141a5d",
+		"9012f1e18a87790d2e01faace75aaaca38e53df437cdce2c0552464dda4af49c",
+		"329bcb82b465308e4d3445408c794db388e401855b1fe6f2981c93ca34ce516b",
+	}
+
+	opt := merkletree.WithProfileName("ethereum")
+	for size := 1; size <= len(roots); size++ {
+		var leaves merkletree.Leaves
+		for i := 0; i < size; i++ {
+			leaves.Add(&merkletree.Leaf{Payload: []byte{'a' + byte(i)}})
+		}
+
+		tree, root, err := leaves.BuildTree(opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, roots[size-1], hex.EncodeToString(root.Hash), "size=%d", size)
+
+		for index := uint64(0); index < uint64(size); index++ {
+			proof, err := tree.GetProof(index, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := proof.Verify(root.Hash, merkletree.ProfileEthereum.HashFunc, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, result, "size=%d index=%d", size, index)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package merkletree_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// reverseHex returns bytes of hex string in reversed order, Bitcoin
+// displays hashes in reversed byte order
+func reverseHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return b
+}
+
+// Build tree of the genesis block, whose merkle root is the txid of its only
+// transaction
+func TestWithProfile_Bitcoin_SingleLeaf(t *testing.T) {
+	txid := reverseHex(t, "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b")
+	leaves := merkletree.Leaves{{Hash: txid}}
+	_, root, err := leaves.BuildTree(merkletree.WithProfile(merkletree.ProfileBitcoin), merkletree.WithSkipHash(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, txid, root.Hash)
+}
+
+// Build tree of Bitcoin block 100000 with bitcoin profile
+func TestWithProfile_Bitcoin(t *testing.T) {
+	txids := []string{
+		"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+		"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+		"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+		"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+	}
+
+	var leaves merkletree.Leaves
+	for _, txid := range txids {
+		leaves.Add(&merkletree.Leaf{Hash: reverseHex(t, txid)})
+	}
+
+	_, root, err := leaves.BuildTree(merkletree.WithProfile(merkletree.ProfileBitcoin), merkletree.WithSkipHash(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, reverseHex(t, "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"), root.Hash)
+}
+
+// Build trees of the test vectors of RFC 6962 with ct profile
+func TestWithProfile_CT(t *testing.T) {
+	inputs := []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+	roots := []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+
+	opt := merkletree.WithProfileName("ct")
+	for size := 1; size <= len(inputs); size++ {
+		var leaves merkletree.Leaves
+		for _, input := range inputs[:size] {
+			payload, err := hex.DecodeString(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			leaves.Add(&merkletree.Leaf{Payload: payload})
+		}
+
+		tree, root, err := leaves.BuildTree(opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, roots[size-1], hex.EncodeToString(root.Hash), "size=%d", size)
+
+		builder := merkletree.NewBuilder(merkletree.WithProfile(merkletree.ProfileCT))
+		for _, input := range inputs[:size] {
+			payload, err := hex.DecodeString(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := builder.Add(payload); err != nil {
+				t.Fatal(err)
+			}
+		}
+		rootHash, err := builder.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, root.Hash, rootHash, "size=%d", size)
+
+		for index := uint64(0); index < uint64(size); index++ {
+			proof, err := tree.GetProof(index, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := proof.Verify(root.Hash, merkletree.ProfileCT.HashFunc, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, result, "size=%d index=%d", size, index)
+		}
+	}
+
+	// Test empty tree, whose root is the hash of an empty string
+	var empty merkletree.Leaves
+	_, root, err := empty.BuildTree(opt, merkletree.WithAllowEmpty(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", hex.EncodeToString(root.Hash))
+}
+
+// Lookup profile by name
+func TestLookupProfile(t *testing.T) {
+	profile, err := merkletree.LookupProfile("bitcoin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, merkletree.ProfileBitcoin == profile)
+	assert.Contains(t, merkletree.ProfileNames(), "bitcoin")
+
+	// Test options of profile
+	opts := merkletree.NewOptions(merkletree.WithProfile(profile))
+	assert.True(t, profile.HashFunc == opts.HashFunc)
+	assert.False(t, opts.SortedPairHashing)
+
+	// Test nil profile
+	opts = merkletree.NewOptions(merkletree.WithSortedPairHashing(true), merkletree.WithProfile(nil))
+	assert.True(t, opts.SortedPairHashing)
+
+	// Test options of profile by name
+	opts = merkletree.NewOptions(merkletree.WithProfileName("ct"))
+	assert.True(t, merkletree.ProfileCT.HashFunc == opts.HashFunc)
+	assert.Equal(t, merkletree.OddPromote, opts.OddRule)
+	assert.Equal(t, []byte{0x00}, opts.LeafTag)
+	assert.Equal(t, []byte{0x01}, opts.NodeTag)
+
+	// Test unknown profile
+	_, err = merkletree.LookupProfile("unknown")
+	if err != nil {
+		t.Log("profile is unknown, lookup failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	_, _, err = mockLeaves(3).BuildTree(merkletree.WithProfileName("unknown"))
+	assert.NotNil(t, err)
+	t.Log("profile is unknown, build tree failed as expected, err=", err)
+}
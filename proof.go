@@ -0,0 +1,372 @@
+package merkletree
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Versions of the binary proof encoding.
+//
+// Policy: MarshalBinary always writes ProofVersion, the latest version.
+// UnmarshalBinary accepts every version listed in SupportedProofVersions, so
+// a fleet of mixed-version verifiers keeps working while producers upgrade.
+// A version is only removed from SupportedProofVersions in a major release,
+// and producers talking to older verifiers can pick a common version with
+// NegotiateProofVersion & write it with MarshalBinaryVersion.
+const (
+	// ProofVersion1 layout: version byte, uvarint index, uvarint hash size,
+	// leaf hash, uvarint path length, then (uvarint y, uvarint x, sibling)
+	// for each level
+	ProofVersion1 byte = 1
+
+	// ProofVersion is the latest version
+	ProofVersion = ProofVersion1
+)
+
+// SupportedProofVersions versions accepted by UnmarshalBinary, ascending
+var SupportedProofVersions = []byte{ProofVersion1}
+
+// Proof is a self-contained merkle proof, which can be verified against a
+// root hash without the tree
+type Proof struct {
+	// Index of the leaf
+	Index uint64
+
+	// Leaf hash
+	Leaf Hash
+
+	// Path is the merkle path in coordinates
+	Path PoNs
+
+	// Siblings are the hashes referred by path, from leaf to the root
+	Siblings []Hash
+}
+
+// GetProof returns proof of the leaf at index, the synthetic duplicate is
+// rejected if the leaf count is configured by WithLeafCount
+func (tree *Tree) GetProof(index uint64, opt ...OptionFunc) (*Proof, error) {
+	path, err := tree.PathForLeaf(index, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := tree.GetHash(0, index)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &Proof{
+		Index:    index,
+		Leaf:     leaf,
+		Path:     path,
+		Siblings: make([]Hash, 0, len(path)),
+	}
+	for _, pon := range path {
+		sibling, err := tree.GetHash(pon[0], pon[1])
+		if err != nil {
+			return nil, err
+		}
+		proof.Siblings = append(proof.Siblings, sibling)
+	}
+
+	return proof, nil
+}
+
+// Verify returns true if the proof leads to root. Returns error if the path
+// is not the one of the leaf at Index, so the index is authenticated too,
+// unless SortedPairHashing is set, which hashes pairs regardless of order.
+// Returns *SizeError if a hash of proof is not of the digest size of h.
+func (proof *Proof) Verify(root []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	opts := NewOptions(append(opt, WithHashFunc(h))...)
+	span := opts.startSpan(SpanVerifyProof)
+
+	result, err := proof.verify(root, h, opts)
+	endSpan(span, err, Attribute{Key: AttrResult, Value: result})
+
+	return result, err
+}
+
+// VerifyContext returns true if the proof leads to root like Verify, the
+// verification is aborted if ctx is done between steps of the path
+func (proof *Proof) VerifyContext(ctx context.Context, root []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	return proof.Verify(root, h, append(opt, WithContext(ctx))...)
+}
+
+// verify returns true if the proof leads to root
+func (proof *Proof) verify(root []byte, h IHashFunc, opts Options) (bool, error) {
+	if proof == nil {
+		return false, errors.New("proof is empty")
+	} else if err := opts.checkDigests(proof); err != nil {
+		return false, err
+	}
+
+	digest, err := proof.computeRoot(h, opts, nil)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(root, digest), nil
+}
+
+// computeRoot returns root hash derived from leaf & siblings, each step is
+// appended to steps if it's not nil
+func (proof *Proof) computeRoot(h IHashFunc, opts Options, steps *[]TranscriptStep) ([]byte, error) {
+	if proof == nil {
+		return nil, errors.New("proof is empty")
+	} else if len(proof.Path) != len(proof.Siblings) {
+		return nil, &SizeError{Name: "number of siblings", Expected: uint64(len(proof.Path)), Actual: uint64(len(proof.Siblings))}
+	}
+
+	if err := checkIndex(proof.Index, proof.Path, opts.OddRule == OddPromote); err != nil {
+		return nil, err
+	}
+
+	digest := []byte(proof.Leaf)
+
+	for i, pon := range proof.Path {
+		if err := opts.Context.Err(); err != nil {
+			return nil, err
+		}
+
+		// The order is taken from the index, which is authenticated with it
+		sibling := proof.Siblings[i]
+		x := proof.Index >> pon[0]
+		if pon[1] == x && !bytes.Equal(sibling, digest) {
+			return nil, &PathError{Step: i, PoN: pon, Err: errors.New("node paired with itself has another sibling")}
+		}
+
+		siblingFirst := x%2 == 1
+		if opts.SortedPairHashing {
+			siblingFirst = bytes.Compare(sibling, digest) <= 0
+		}
+
+		var err error
+		if siblingFirst {
+			digest, err = hashPair(h, opts.NodeTag, sibling, digest, false)
+		} else {
+			digest, err = hashPair(h, opts.NodeTag, digest, sibling, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if steps != nil {
+			order := OrderDigestSibling
+			if siblingFirst {
+				order = OrderSiblingDigest
+			}
+			*steps = append(*steps, TranscriptStep{
+				Level:   pon[0],
+				PoN:     pon,
+				Sibling: sibling,
+				Order:   order,
+				Digest:  digest,
+			})
+		}
+	}
+
+	return digest, nil
+}
+
+// checkIndex returns error if path is not the one of the leaf at index,
+// whose position of each level is the brother of the node on the way to the
+// root, or the node itself if it's the last one paired with itself. If the
+// last node is promoted instead, the path skips the levels the node is
+// promoted, where the node must be the last one of even position.
+func checkIndex(index uint64, path PoNs, promote bool) error {
+	level := uint64(0)
+	for i, pon := range path {
+		if promote && pon[0] > level && pon[0] < 64 {
+			if (index>>level)&(1<<(pon[0]-level)-1) != 0 {
+				return &PathError{Step: i, PoN: pon, Err: fmt.Errorf("node of leaf %d is not promoted to level %d", index, pon[0])}
+			}
+			level = pon[0]
+		}
+
+		x := index >> level
+		if pon[0] != level || (pon[1] != x^1 && (promote || !(pon[1] == x && x%2 == 0))) {
+			return &PathError{Step: i, PoN: pon, Err: fmt.Errorf("not brother of node (%d,%d) of leaf %d", level, x, index)}
+		}
+		level++
+	}
+
+	if level < 64 && index>>level != 0 {
+		return &IndexError{Index: index, Count: 1 << level}
+	}
+
+	return nil
+}
+
+// MarshalBinary returns bytes of proof in the latest version
+func (proof *Proof) MarshalBinary() ([]byte, error) {
+	return proof.MarshalBinaryVersion(ProofVersion)
+}
+
+// MarshalBinaryVersion returns bytes of proof in version
+func (proof *Proof) MarshalBinaryVersion(version byte) ([]byte, error) {
+	if proof == nil {
+		return nil, errors.New("proof is empty")
+	}
+
+	switch version {
+	case ProofVersion1:
+		return proof.marshalV1()
+	default:
+		return nil, fmt.Errorf("unsupported proof version %d", version)
+	}
+}
+
+// UnmarshalBinary decode proof of any supported version
+func (proof *Proof) UnmarshalBinary(data []byte) error {
+	return proof.unmarshal(data, DecodeLimits{})
+}
+
+// UnmarshalProof returns proof from bytes of any supported version, the
+// proof is rejected if it exceeds limits configured by WithDecodeLimits, or
+// if a hash is not of the digest size of the hash function
+func UnmarshalProof(data []byte, opt ...OptionFunc) (*Proof, error) {
+	opts := NewOptions(opt...)
+
+	proof := &Proof{}
+	if err := proof.unmarshal(data, opts.DecodeLimits); err != nil {
+		return nil, err
+	} else if err := opts.checkDigests(proof); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// unmarshal decode proof of any supported version within limits
+func (proof *Proof) unmarshal(data []byte, limits DecodeLimits) error {
+	if len(data) == 0 {
+		return errors.New("data is empty")
+	} else if err := limits.checkBytes(len(data)); err != nil {
+		return err
+	}
+
+	switch data[0] {
+	case ProofVersion1:
+		return proof.unmarshalV1(data[1:], limits)
+	default:
+		return fmt.Errorf("unsupported proof version %d", data[0])
+	}
+}
+
+// NegotiateProofVersion returns the latest version supported by both sides
+func NegotiateProofVersion(peerVersions []byte) (byte, error) {
+	for i := len(SupportedProofVersions) - 1; i >= 0; i-- {
+		for _, peerVersion := range peerVersions {
+			if peerVersion == SupportedProofVersions[i] {
+				return peerVersion, nil
+			}
+		}
+	}
+
+	return 0, errors.New("not found common proof version")
+}
+
+// marshalV1 returns bytes of proof in version 1
+func (proof *Proof) marshalV1() ([]byte, error) {
+	if len(proof.Path) != len(proof.Siblings) {
+		return nil, errors.New("path & siblings mismatch")
+	}
+
+	size := len(proof.Leaf)
+	for i, sibling := range proof.Siblings {
+		if len(sibling) != size {
+			return nil, &SizeError{Name: fmt.Sprintf("hash size of sibling %d", i), Expected: uint64(size), Actual: uint64(len(sibling))}
+		}
+	}
+
+	buf := []byte{ProofVersion1}
+	buf = appendUvarint(buf, proof.Index)
+	buf = appendUvarint(buf, uint64(size))
+	buf = append(buf, proof.Leaf...)
+	buf = appendUvarint(buf, uint64(len(proof.Path)))
+	for i, pon := range proof.Path {
+		buf = appendUvarint(buf, pon[0])
+		buf = appendUvarint(buf, pon[1])
+		buf = append(buf, proof.Siblings[i]...)
+	}
+
+	return buf, nil
+}
+
+// unmarshalV1 decode proof in version 1 within limits, data is without the
+// version byte
+func (proof *Proof) unmarshalV1(data []byte, limits DecodeLimits) error {
+	r := bytes.NewReader(data)
+
+	index, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	} else if size == 0 || size > uint64(r.Len()) {
+		return errors.New("invalid hash size")
+	} else if err := limits.checkHashSize(size); err != nil {
+		return err
+	}
+
+	leaf := make(Hash, size)
+	if _, err := io.ReadFull(r, leaf); err != nil {
+		return err
+	}
+
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	} else if length > uint64(r.Len())/(size+2) {
+		// Each step is at least 2 bytes of position & a sibling
+		return errors.New("invalid path length")
+	} else if err := limits.checkPathLength(length); err != nil {
+		return err
+	}
+
+	path := make(PoNs, 0, length)
+	siblings := make([]Hash, 0, length)
+	for i := uint64(0); i < length; i++ {
+		y, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+
+		sibling := make(Hash, size)
+		if _, err := io.ReadFull(r, sibling); err != nil {
+			return err
+		}
+
+		path = append(path, PoN{y, x})
+		siblings = append(siblings, sibling)
+	}
+
+	if r.Len() != 0 {
+		return errors.New("unexpected trailing data")
+	}
+
+	proof.Index = index
+	proof.Leaf = leaf
+	proof.Path = path
+	proof.Siblings = siblings
+
+	return nil
+}
+
+// appendUvarint append uvarint of v to buf
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
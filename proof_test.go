@@ -0,0 +1,178 @@
+package merkletree_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Get proof & verify it without tree
+func TestTree_GetProof(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index := uint64(0); index <= tree.X(0); index++ {
+		proof, err := tree.GetProof(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := proof.Verify(root.Hash, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result, "index=%d", index)
+	}
+
+	// Test bad leaf
+	proof, err := tree.GetProof(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, goodHash, []byte(proof.Leaf))
+	proof.Leaf = badHash
+	result, err := proof.Verify(root.Hash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, result)
+
+	// Test tampered index, the path is of another leaf
+	proof.Leaf = goodHash
+	for _, index := range []uint64{3, 5, 2 + 1<<uint(len(proof.Path))} {
+		proof.Index = index
+		result, err = proof.Verify(root.Hash, h)
+		if err != nil {
+			t.Log("index is tampered, verify failed as expected, err=", err)
+		}
+		assert.NotNil(t, err, "index=%d", index)
+		assert.False(t, result)
+	}
+
+	// Test odd index claimed to be a node paired with itself
+	proof, err = tree.GetProof(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Index--
+	_, err = proof.Verify(root.Hash, h)
+	assert.NotNil(t, err)
+
+	// Test invalid index
+	_, err = tree.GetProof(tree.Width(0))
+	if err != nil {
+		t.Log("index is out of range, get proof failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test invalid proof
+	var invalidProof *merkletree.Proof
+	_, err = invalidProof.Verify(root.Hash, h)
+	assert.NotNil(t, err)
+}
+
+// Marshal & unmarshal proof in binary
+func TestProof_MarshalBinary(t *testing.T) {
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof1, err := tree.GetProof(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes1, err := proof1.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("ProofMarshal=", merkletree.Hex(bytes1))
+	assert.Equal(t, merkletree.ProofVersion, bytes1[0])
+
+	var proof2 merkletree.Proof
+	if err := proof2.UnmarshalBinary(bytes1); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *proof1, proof2)
+
+	// Test unsupported version
+	bytes2 := append([]byte{0xff}, bytes1[1:]...)
+	err = proof2.UnmarshalBinary(bytes2)
+	if err != nil {
+		t.Log("version is unsupported, unmarshal failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	_, err = proof1.MarshalBinaryVersion(0xff)
+	assert.NotNil(t, err)
+
+	// Test truncated data
+	for i := 0; i < len(bytes1); i++ {
+		assert.NotNil(t, proof2.UnmarshalBinary(bytes1[:i]), "length=%d", i)
+	}
+
+	// Test trailing data
+	assert.NotNil(t, proof2.UnmarshalBinary(append(bytes1, 0)))
+
+	// Test empty hash with a huge path length
+	bytes3 := []byte{merkletree.ProofVersion1, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+	err = proof2.UnmarshalBinary(bytes3)
+	if err != nil {
+		t.Log("hash size is zero, unmarshal failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	_, err = merkletree.UnmarshalProof(bytes3, merkletree.WithDecodeLimits(merkletree.DefaultDecodeLimits))
+	assert.NotNil(t, err)
+
+	// Test huge path length
+	bytes4 := []byte{merkletree.ProofVersion1, 0, 1, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+	assert.NotNil(t, proof2.UnmarshalBinary(bytes4))
+}
+
+// Negotiate proof version with peer
+func TestNegotiateProofVersion(t *testing.T) {
+	version, err := merkletree.NegotiateProofVersion([]byte{merkletree.ProofVersion1, 0xfe})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, merkletree.ProofVersion1, version)
+
+	_, err = merkletree.NegotiateProofVersion([]byte{0xfe})
+	if err != nil {
+		t.Log("no common version, negotiate failed as expected")
+	}
+	assert.NotNil(t, err)
+}
+
+// Verify proof with context
+func TestProof_VerifyContext(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := tree.GetProof(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := proof.VerifyContext(context.Background(), root.Hash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = proof.VerifyContext(ctx, root.Hash, h)
+	assert.Equal(t, context.Canceled, err)
+	t.Log("context is canceled, verify failed as expected")
+}
@@ -0,0 +1,65 @@
+package merkletree
+
+import (
+	"errors"
+)
+
+// RangeRoot returns root hash of leaves [i, j), as if a tree was built from
+// these leaves only. The synthetic duplicate is out of range if the leaf
+// count is configured by WithLeafCount.
+func (tree *Tree) RangeRoot(i uint64, j uint64, h IHashFunc, opt ...OptionFunc) ([]byte, error) {
+	if err := tree.checkRange(i, j); err != nil {
+		return nil, err
+	}
+
+	count, err := tree.leafCount(NewOptions(opt...))
+	if err != nil {
+		return nil, err
+	} else if j > count {
+		return nil, &IndexError{Index: j - 1, Count: count}
+	}
+
+	builder := NewBuilder(append(opt, WithHashFunc(h))...)
+	for x := i; x < j; x++ {
+		if err := builder.AddHash((*tree)[0][x]); err != nil {
+			return nil, err
+		}
+	}
+
+	return builder.Root()
+}
+
+// RangeProof returns merkle path linking root of leaves [i, j) to the root
+// of tree. The range must be an aligned subtree: j-i is a power of 2 larger
+// than 1, and i is a multiple of j-i. The root of range can be proved by
+// Prove with the path. Options configure the odd rule like PathForLeaf.
+func (tree *Tree) RangeProof(i uint64, j uint64, opt ...OptionFunc) (PoNs, error) {
+	if err := tree.checkRange(i, j); err != nil {
+		return nil, err
+	}
+
+	size := j - i
+	if size < 2 || size&(size-1) != 0 || i%size != 0 {
+		return nil, errors.New("range is not an aligned subtree")
+	}
+
+	y := uint64(0)
+	for s := size; s > 1; s >>= 1 {
+		y++
+	}
+
+	return tree.pathFrom(y, i>>y, NewOptions(opt...).OddRule == OddPromote), nil
+}
+
+// checkRange returns error if [i, j) is not a valid range of leaves
+func (tree *Tree) checkRange(i uint64, j uint64) error {
+	if tree == nil || tree.Height() == 0 {
+		return errors.New("tree is empty")
+	} else if i >= j {
+		return errors.New("invalid range")
+	} else if j > tree.Width(0) {
+		return &IndexError{Index: j - 1, Count: tree.Width(0)}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Get root of leaf range
+func TestTree_RangeRoot(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, root, err := mockLeaves(32).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test range of whole tree
+	rangeRoot, err := tree.RangeRoot(0, 32, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, rangeRoot)
+
+	// Test range equals to tree built from the range
+	for _, r := range [][2]uint64{{0, 1}, {3, 4}, {5, 12}, {8, 16}, {17, 32}} {
+		rangeRoot, err := tree.RangeRoot(r[0], r[1], h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaves := (*mockLeaves(32))[r[0]:r[1]]
+		_, expected, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected.Hash, rangeRoot, "range=%v", r)
+	}
+
+	// Test invalid range
+	_, err = tree.RangeRoot(4, 4, h)
+	if err != nil {
+		t.Log("range is empty, get range root failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	_, err = tree.RangeRoot(0, 33, h)
+	assert.NotNil(t, err)
+
+	var invalidTree1 *merkletree.Tree
+	_, err = invalidTree1.RangeRoot(0, 1, h)
+	assert.NotNil(t, err)
+}
+
+// Prove root of leaf range
+func TestTree_RangeProof(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, _, err := mockLeaves(27).BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range [][2]uint64{{0, 2}, {6, 8}, {8, 16}, {16, 24}, {0, 16}} {
+		merklePath, err := tree.RangeProof(r[0], r[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rangeRoot, err := tree.RangeRoot(r[0], r[1], h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := tree.Prove(&merklePath, rangeRoot, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result, "range=%v", r)
+	}
+
+	// Test unaligned ranges
+	for _, r := range [][2]uint64{{0, 1}, {1, 3}, {0, 3}, {4, 12}} {
+		_, err := tree.RangeProof(r[0], r[1])
+		if err != nil {
+			t.Log("range is not aligned, get range proof failed as expected")
+		}
+		assert.NotNil(t, err, "range=%v", r)
+	}
+}
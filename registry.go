@@ -0,0 +1,68 @@
+package merkletree
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Names of built-in hash functions
+const (
+	// HashSHA256 is SHA-256
+	HashSHA256 = "sha256"
+
+	// HashDoubleSHA256 is SHA-256 applied twice, as used by Bitcoin
+	HashDoubleSHA256 = "sha256d"
+
+	// HashKeccak256 is legacy Keccak-256, not available in builds with tag
+	// 'merkletree_sha256'
+	HashKeccak256 = "keccak256"
+)
+
+var (
+	// hashFuncs registered hash functions by name
+	hashFuncs   = make(map[string]IHashFunc)
+	hashFuncsMu sync.RWMutex
+)
+
+func init() {
+	RegisterHashFunc(HashSHA256, &HashFunc{Provider: sha256.New})
+	RegisterHashFunc(HashDoubleSHA256, &DoubleHashFunc{Provider: sha256.New})
+}
+
+// RegisterHashFunc register hash function by name, so it can be referenced
+// by serialized artifacts
+func RegisterHashFunc(name string, h IHashFunc) {
+	hashFuncsMu.Lock()
+	defer hashFuncsMu.Unlock()
+
+	hashFuncs[name] = h
+}
+
+// GetHashFunc returns hash function registered by name
+func GetHashFunc(name string) (IHashFunc, error) {
+	hashFuncsMu.RLock()
+	defer hashFuncsMu.RUnlock()
+
+	h, ok := hashFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash function '%s'", name)
+	}
+
+	return h, nil
+}
+
+// HashFuncNames returns names of registered hash functions in sorted order
+func HashFuncNames() []string {
+	hashFuncsMu.RLock()
+	defer hashFuncsMu.RUnlock()
+
+	names := make([]string, 0, len(hashFuncs))
+	for name := range hashFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,28 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Register & get hash function by name
+func TestRegisterHashFunc(t *testing.T) {
+	custom := GetCustomHashFunc()
+	merkletree.RegisterHashFunc("custom", custom)
+
+	h, err := merkletree.GetHashFunc("custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, custom == h)
+	assert.Contains(t, merkletree.HashFuncNames(), "custom")
+	assert.Contains(t, merkletree.HashFuncNames(), merkletree.HashSHA256)
+
+	_, err = merkletree.GetHashFunc("unknown")
+	if err != nil {
+		t.Log("hash function is unknown, get failed as expected")
+	}
+	assert.NotNil(t, err)
+}
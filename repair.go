@@ -0,0 +1,73 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Verify returns positions of branches which are inconsistent with their
+// children, the tree is not modified. Leaves cannot be verified without
+// payloads.
+func (tree *Tree) Verify(h IHashFunc, opt ...OptionFunc) (PoNs, error) {
+	return tree.check(h, NewOptions(opt...), false)
+}
+
+// Repair recomputes branches which are inconsistent with their children
+// bottom-up, returns positions of fixed branches. Leaves are trusted.
+func (tree *Tree) Repair(h IHashFunc, opt ...OptionFunc) (PoNs, error) {
+	return tree.check(h, NewOptions(opt...), true)
+}
+
+// check compares each branch with the hash of its children, fix it if fix
+// is true. It's aborted if ctx is done between levels
+func (tree *Tree) check(h IHashFunc, opts Options, fix bool) (PoNs, error) {
+	if tree == nil || tree.Height() == 0 {
+		return nil, errors.New("tree is empty")
+	} else if err := opts.checkOddRule(); err != nil {
+		return nil, err
+	}
+
+	pons := make(PoNs, 0)
+	for y := uint64(1); y < tree.Height(); y++ {
+		if err := opts.Context.Err(); err != nil {
+			return nil, err
+		}
+
+		if expected := (tree.Width(y-1) + 1) / 2; tree.Width(y) != expected {
+			return nil, &SizeError{Name: fmt.Sprintf("width of level %d", y), Expected: expected, Actual: tree.Width(y)}
+		}
+
+		for x := uint64(0); x < tree.Width(y); x++ {
+			digest, err := tree.branchHash(h, opts, y, x)
+			if err != nil {
+				return nil, err
+			}
+
+			if bytes.Equal(digest, (*tree)[y][x]) {
+				continue
+			}
+
+			pons = append(pons, PoN{y, x})
+			if fix {
+				(*tree)[y][x] = digest
+			}
+		}
+	}
+
+	return pons, nil
+}
+
+// branchHash returns hash of children of branch (y,x), y must be > 0. A
+// child without brother is the branch itself if the odd rule is OddPromote
+func (tree *Tree) branchHash(h IHashFunc, opts Options, y uint64, x uint64) ([]byte, error) {
+	left, right := 2*x, 2*x+1
+	if right > tree.X(y-1) {
+		if opts.OddRule == OddPromote {
+			return (*tree)[y-1][left], nil
+		}
+		right = left
+	}
+
+	return hashPair(h, opts.NodeTag, (*tree)[y-1][left], (*tree)[y-1][right], opts.SortedPairHashing)
+}
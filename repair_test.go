@@ -0,0 +1,59 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Verify & repair corrupted tree
+func TestTree_Repair(t *testing.T) {
+	h := GetCustomHashFunc()
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := tree.Clone()
+
+	// Test consistent tree
+	pons, err := tree.Verify(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, pons)
+
+	// Corrupt branches
+	(*tree)[1][2] = append([]byte{}, badHash...)
+	(*tree)[3][1] = append([]byte{}, badHash...)
+
+	pons, err = tree.Verify(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Inconsistent=", pons)
+	assert.Contains(t, pons, merkletree.PoN{1, 2})
+	assert.Contains(t, pons, merkletree.PoN{3, 1})
+
+	// Repair
+	pons, err = tree.Repair(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Fixed=", pons)
+	assert.Equal(t, merkletree.PoNs{{1, 2}, {3, 1}}, pons)
+	assert.Equal(t, *expected, *tree)
+
+	// Test invalid width
+	(*tree)[1] = (*tree)[1][:2]
+	_, err = tree.Repair(h)
+	if err != nil {
+		t.Log("width is invalid, repair failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	// Test invalid tree
+	var invalidTree1 *merkletree.Tree
+	_, err = invalidTree1.Repair(h)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,318 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FormatVersion is the current version of serialized artifacts. Version 0
+// is the unversioned output of Tree.Marshal & Root.Marshal.
+const FormatVersion = 1
+
+// Kinds of serialized artifacts
+const (
+	// KindTree is a Tree
+	KindTree = "tree"
+
+	// KindRoot is a Root
+	KindRoot = "root"
+
+	// KindPath is a merkle path (PoNs)
+	KindPath = "path"
+)
+
+// Envelope is the versioned container of serialized artifacts
+type Envelope struct {
+	// Version of the format
+	Version int `json:"version"`
+
+	// Kind of the artifact
+	Kind string `json:"kind"`
+
+	// Compression is the name of the compressor of data, empty if data is
+	// not compressed. Compressed data is a base64 JSON string.
+	Compression string `json:"compression,omitempty"`
+
+	// Data of the artifact
+	Data json.RawMessage `json:"data"`
+}
+
+// migration upgrades data of an artifact by one version
+type migration func(kind string, data json.RawMessage) (json.RawMessage, error)
+
+// migrations by the version they upgrade from
+var migrations = map[int]migration{
+	// Version 0 has the same layout, it's only wrapped by the envelope
+	0: func(kind string, data json.RawMessage) (json.RawMessage, error) {
+		return data, nil
+	},
+}
+
+// MarshalVersioned returns bytes of tree in the versioned envelope, data is
+// compressed if configured by WithCompression. A tree without leaves has no
+// levels, its root is reported by GetRootHash with AllowEmpty.
+func (tree *Tree) MarshalVersioned(opt ...OptionFunc) ([]byte, error) {
+	data, err := tree.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalEnvelope(KindTree, data, NewOptions(opt...))
+}
+
+// MarshalVersioned returns bytes of root in the versioned envelope, data is
+// compressed if configured by WithCompression
+func (node *Root) MarshalVersioned(opt ...OptionFunc) ([]byte, error) {
+	if node == nil {
+		return nil, errors.New("root is empty")
+	}
+
+	data, err := node.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalEnvelope(KindRoot, data, NewOptions(opt...))
+}
+
+// MarshalVersioned returns bytes of merkle path in the versioned envelope,
+// data is compressed if configured by WithCompression
+func (pons *PoNs) MarshalVersioned(opt ...OptionFunc) ([]byte, error) {
+	if pons == nil {
+		return nil, errors.New("path is empty")
+	}
+
+	data, err := json.Marshal(pons)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalEnvelope(KindPath, data, NewOptions(opt...))
+}
+
+// UnmarshalTree returns tree from bytes of any supported version, the tree
+// is rejected if it exceeds limits configured by WithDecodeLimits, or if a
+// hash is not of the digest size of the hash function
+func UnmarshalTree(data []byte, opt ...OptionFunc) (*Tree, error) {
+	opts := NewOptions(opt...)
+
+	envelope, err := readEnvelope(KindTree, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := opts.DecodeLimits.decodeTree(envelope.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.checkDigests(&tree); err != nil {
+		return nil, err
+	}
+
+	return &tree, nil
+}
+
+// UnmarshalRoot returns root from bytes of any supported version, the root
+// is rejected if it exceeds limits configured by WithDecodeLimits, or if a
+// hash is not of the digest size of the hash function
+func UnmarshalRoot(data []byte, opt ...OptionFunc) (*Root, error) {
+	opts := NewOptions(opt...)
+
+	envelope, err := readEnvelope(KindRoot, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var root Root
+	if err := json.Unmarshal(envelope.Data, &root); err != nil {
+		return nil, err
+	}
+
+	if err := opts.DecodeLimits.checkRoot(&root); err != nil {
+		return nil, err
+	}
+
+	if err := opts.checkDigests(&root); err != nil {
+		return nil, err
+	}
+
+	return &root, nil
+}
+
+// UnmarshalPath returns merkle path from bytes of any supported version,
+// the path is rejected if it exceeds limits configured by WithDecodeLimits
+func UnmarshalPath(data []byte, opt ...OptionFunc) (*PoNs, error) {
+	opts := NewOptions(opt...)
+
+	envelope, err := readEnvelope(KindPath, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var pons PoNs
+	if err := json.Unmarshal(envelope.Data, &pons); err != nil {
+		return nil, err
+	}
+
+	if err := opts.DecodeLimits.checkPathLength(uint64(len(pons))); err != nil {
+		return nil, err
+	}
+
+	return &pons, nil
+}
+
+// Upgrade reads an artifact of kind in any supported version, returns it
+// in the current version without compression. The artifact is rejected if
+// it exceeds MaxBytes of limits configured by WithDecodeLimits, before or
+// after decompression. MaxBytes of DefaultDecodeLimits is applied if it's
+// not configured.
+func Upgrade(kind string, data []byte, opt ...OptionFunc) ([]byte, error) {
+	opts := NewOptions(opt...)
+	if opts.DecodeLimits.MaxBytes == 0 {
+		opts.DecodeLimits.MaxBytes = DefaultDecodeLimits.MaxBytes
+	}
+
+	envelope, err := readEnvelope(kind, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(envelope)
+}
+
+// marshalEnvelope returns bytes of data in the envelope of current version,
+// data is compressed by the compressor of options
+func marshalEnvelope(kind string, data []byte, opts Options) ([]byte, error) {
+	envelope := Envelope{
+		Version: FormatVersion,
+		Kind:    kind,
+		Data:    data,
+	}
+
+	if opts.Compression != "" {
+		c, err := GetCompressor(opts.Compression)
+		if err != nil {
+			return nil, err
+		}
+
+		compressed, err := c.Compress(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if envelope.Data, err = json.Marshal(compressed); err != nil {
+			return nil, err
+		}
+		envelope.Compression = opts.Compression
+	}
+
+	return json.Marshal(envelope)
+}
+
+// decompress replace compressed data of envelope with the original data,
+// which must not exceed maxBytes if it's > 0
+func (envelope *Envelope) decompress(maxBytes int) error {
+	if envelope.Compression == "" {
+		return nil
+	}
+
+	c, err := GetCompressor(envelope.Compression)
+	if err != nil {
+		return err
+	}
+
+	var compressed []byte
+	if err := json.Unmarshal(envelope.Data, &compressed); err != nil {
+		return err
+	}
+
+	var data []byte
+	if lc, ok := c.(LimitedDecompressor); ok && maxBytes > 0 {
+		data, err = lc.DecompressLimit(compressed, maxBytes)
+	} else {
+		data, err = c.Decompress(compressed)
+	}
+	if err != nil {
+		return err
+	} else if err := checkLimit("decompressed size", maxBytes, uint64(len(data))); err != nil {
+		return err
+	}
+
+	envelope.Data = data
+	envelope.Compression = ""
+
+	return nil
+}
+
+// readEnvelope decode bytes of kind, returns envelope upgraded to the
+// current version. Bytes without envelope are read as version 0.
+func readEnvelope(kind string, data []byte, opts Options) (*Envelope, error) {
+	if err := opts.DecodeLimits.checkBytes(len(data)); err != nil {
+		return nil, err
+	}
+
+	envelope, err := decodeEnvelope(kind, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if envelope.Kind != kind {
+		return nil, fmt.Errorf("invalid kind '%s', expected '%s'", envelope.Kind, kind)
+	} else if envelope.Version < 0 || envelope.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported version %d", envelope.Version)
+	}
+
+	if err := envelope.decompress(opts.DecodeLimits.MaxBytes); err != nil {
+		return nil, err
+	}
+
+	for envelope.Version < FormatVersion {
+		migrate, ok := migrations[envelope.Version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from version %d", envelope.Version)
+		}
+
+		upgraded, err := migrate(kind, envelope.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		envelope.Data = upgraded
+		envelope.Version++
+	}
+
+	return envelope, nil
+}
+
+// decodeEnvelope decode bytes as envelope, or as version 0 if bytes is not
+// an envelope
+func decodeEnvelope(kind string, data []byte) (*Envelope, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("data is empty")
+	}
+
+	if data[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+
+		if _, ok := fields["version"]; ok {
+			var envelope Envelope
+			if err := json.Unmarshal(data, &envelope); err != nil {
+				return nil, err
+			}
+			return &envelope, nil
+		}
+	}
+
+	return &Envelope{
+		Version: 0,
+		Kind:    kind,
+		Data:    data,
+	}, nil
+}
@@ -0,0 +1,246 @@
+package merkletree_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Marshal & unmarshal tree in versioned envelope
+func TestTree_MarshalVersioned(t *testing.T) {
+	tree1, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes1, err := tree1.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("TreeMarshalString=", string(bytes1))
+
+	var envelope merkletree.Envelope
+	if err := json.Unmarshal(bytes1, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, merkletree.FormatVersion, envelope.Version)
+	assert.Equal(t, merkletree.KindTree, envelope.Kind)
+
+	tree2, err := merkletree.UnmarshalTree(bytes1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *tree1, *tree2)
+
+	// Test wrong kind
+	_, err = merkletree.UnmarshalRoot(bytes1)
+	if err != nil {
+		t.Log("kind is tree, unmarshal root failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test unsupported version
+	envelope.Version = merkletree.FormatVersion + 1
+	bytes2, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = merkletree.UnmarshalTree(bytes2)
+	if err != nil {
+		t.Log("version is unsupported, unmarshal tree failed as expected")
+	}
+	assert.NotNil(t, err)
+}
+
+// Marshal & unmarshal root in versioned envelope
+func TestRoot_MarshalVersioned(t *testing.T) {
+	_, root1, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bytes1, err := root1.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root2, err := merkletree.UnmarshalRoot(bytes1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root1.Hash, root2.Hash)
+	assert.Equal(t, root1.Height, root2.Height)
+
+	var invalidRoot *merkletree.Root
+	_, err = invalidRoot.MarshalVersioned()
+	assert.NotNil(t, err)
+}
+
+// Marshal & unmarshal merkle path in versioned envelope
+func TestPoNs_MarshalVersioned(t *testing.T) {
+	pons1 := make(merkletree.PoNs, 0)
+	pons1.GetPath(5, 0, 1)
+
+	bytes1, err := pons1.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("PathMarshalString=", string(bytes1))
+
+	pons2, err := merkletree.UnmarshalPath(bytes1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, pons1, *pons2)
+}
+
+// Upgrade artifacts of version 0
+func TestUpgrade(t *testing.T) {
+	tree1, root1, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Upgrade tree
+	legacyTree, err := tree1.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgradedTree, err := merkletree.Upgrade(merkletree.KindTree, legacyTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTree, err := tree1.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expectedTree, upgradedTree)
+
+	// Read legacy tree directly
+	tree2, err := merkletree.UnmarshalTree(legacyTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *tree1, *tree2)
+
+	// Upgrade root
+	legacyRoot, err := root1.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgradedRoot, err := merkletree.Upgrade(merkletree.KindRoot, legacyRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root2, err := merkletree.UnmarshalRoot(upgradedRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root1.Hash, root2.Hash)
+
+	// Test empty data
+	_, err = merkletree.Upgrade(merkletree.KindTree, nil)
+	if err != nil {
+		t.Log("data is empty, upgrade failed as expected")
+	}
+	assert.NotNil(t, err)
+}
+
+// upperCompressor compressor for test, which is reversible & obvious
+type upperCompressor struct{}
+
+func (upperCompressor) Compress(data []byte) ([]byte, error) {
+	return append([]byte("upper:"), data...), nil
+}
+
+func (upperCompressor) Decompress(data []byte) ([]byte, error) {
+	return data[len("upper:"):], nil
+}
+
+// Marshal & unmarshal compressed envelopes
+func TestMarshalVersioned_Compression(t *testing.T) {
+	merkletree.RegisterCompressor("upper", upperCompressor{})
+	assert.Contains(t, merkletree.CompressorNames(), merkletree.CompressionGzip)
+	assert.Contains(t, merkletree.CompressorNames(), "upper")
+
+	tree1, root1, err := mockLeaves(100).BuildTree(merkletree.WithHashFunc(GetCustomHashFunc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := tree1.MarshalVersioned()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{merkletree.CompressionGzip, "upper"} {
+		bytes1, err := tree1.MarshalVersioned(merkletree.WithCompression(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var envelope merkletree.Envelope
+		if err := json.Unmarshal(bytes1, &envelope); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, name, envelope.Compression)
+
+		tree2, err := merkletree.UnmarshalTree(bytes1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, *tree1, *tree2)
+
+		// Upgrade returns envelope without compression
+		upgraded, err := merkletree.Upgrade(merkletree.KindTree, bytes1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.JSONEq(t, string(plain), string(upgraded))
+
+		bytes2, err := root1.MarshalVersioned(merkletree.WithCompression(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		root2, err := merkletree.UnmarshalRoot(bytes2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, root1.Hash, root2.Hash)
+	}
+
+	gzipped, err := tree1.MarshalVersioned(merkletree.WithCompression(merkletree.CompressionGzip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Plain=", len(plain), "Gzipped=", len(gzipped))
+	assert.Less(t, len(gzipped), len(plain))
+
+	// Test unknown compressor
+	_, err = tree1.MarshalVersioned(merkletree.WithCompression("unknown"))
+	assert.NotNil(t, err)
+
+	var envelope merkletree.Envelope
+	if err := json.Unmarshal(gzipped, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	envelope.Compression = "unknown"
+	bytes1, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = merkletree.UnmarshalTree(bytes1)
+	assert.NotNil(t, err)
+	t.Log("compressor is unknown, unmarshal tree failed as expected")
+
+	// Test corrupted data
+	envelope.Compression = merkletree.CompressionGzip
+	envelope.Data = json.RawMessage(`"AAAA"`)
+	bytes1, err = json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = merkletree.UnmarshalTree(bytes1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,110 @@
+package merkletree
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ShardSummary is the summary of a shard of leaves computed by a worker,
+// the coordinator combines summaries into the root without the leaves
+type ShardSummary struct {
+	// Root hash of the shard
+	Root HexBytes `json:"root"`
+
+	// Size is the number of leaves in the shard
+	Size uint64 `json:"size"`
+}
+
+// Summarize returns summary of the leaves as a shard. Leaves are not
+// modified, except the hash of a streamed payload is stored in its leaf like
+// Builder.AddLeaves
+func (obj *Leaves) Summarize(opt ...OptionFunc) (*ShardSummary, error) {
+	builder := NewBuilder(opt...)
+	if err := builder.AddLeaves(obj); err != nil {
+		return nil, err
+	}
+
+	root, err := builder.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShardSummary{
+		Root: root,
+		Size: builder.Count(),
+	}, nil
+}
+
+// CombineShards returns root hash of consecutive shards, which is the same
+// as the root of a tree built from all leaves of the shards. All shards but
+// the last must have the same size, a power of 2 larger than 1; the last
+// shard can be smaller.
+func CombineShards(shards []ShardSummary, opt ...OptionFunc) ([]byte, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("not found shard")
+	} else if len(shards) == 1 {
+		return shards[0].Root, nil
+	}
+
+	size := shards[0].Size
+	if size < 2 || size&(size-1) != 0 {
+		return nil, fmt.Errorf("invalid shard size %d, must be a power of 2 larger than 1", size)
+	}
+
+	for i, shard := range shards {
+		if i < len(shards)-1 && shard.Size != size {
+			return nil, &SizeError{Name: fmt.Sprintf("size of shard %d", i), Expected: size, Actual: shard.Size}
+		} else if shard.Size == 0 || shard.Size > size {
+			return nil, fmt.Errorf("invalid size %d of shard %d", shard.Size, i)
+		}
+	}
+
+	opts := NewOptions(opt...)
+	if err := opts.checkBinary(); err != nil {
+		return nil, err
+	} else if err := opts.checkOddRule(); err != nil {
+		return nil, err
+	}
+
+	// The root of the last shard is paired with itself up to the level of
+	// the other shard roots, or promoted to it if the odd rule is OddPromote
+	last := shards[len(shards)-1]
+	lastRoot := []byte(last.Root)
+	lastHeight := shardHeight(last.Size)
+	if last.Size == 1 && opts.SingleLeafRoot {
+		// The root of a single leaf is the leaf itself
+		lastHeight = 0
+	}
+	if opts.OddRule == OddPromote {
+		lastHeight = shardHeight(size)
+	}
+	for height := lastHeight; height < shardHeight(size); height++ {
+		digest, err := hashPair(opts.HashFunc, opts.NodeTag, lastRoot, lastRoot, opts.SortedPairHashing)
+		if err != nil {
+			return nil, err
+		}
+		lastRoot = digest
+	}
+
+	builder := NewBuilder(opt...)
+	for _, shard := range shards[:len(shards)-1] {
+		if err := builder.AddHash(shard.Root); err != nil {
+			return nil, err
+		}
+	}
+	if err := builder.AddHash(lastRoot); err != nil {
+		return nil, err
+	}
+
+	return builder.Root()
+}
+
+// shardHeight returns level of the root of a tree with size leaves
+func shardHeight(size uint64) uint64 {
+	height := uint64(1)
+	for width := uint64(2); width < size; width <<= 1 {
+		height++
+	}
+
+	return height
+}
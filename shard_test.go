@@ -0,0 +1,81 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Combine shard summaries computed by workers
+func TestCombineShards(t *testing.T) {
+	for _, sorted := range []bool{false, true} {
+		opts := []merkletree.OptionFunc{
+			merkletree.WithHashFunc(GetCustomHashFunc()),
+			merkletree.WithSortedPairHashing(sorted),
+		}
+
+		for _, shardSize := range []int{2, 4, 8} {
+			for size := 1; size <= 37; size++ {
+				_, root, err := mockLeaves(size).BuildTree(opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Workers
+				leaves := *mockLeaves(size)
+				var shards []merkletree.ShardSummary
+				for i := 0; i < size; i += shardSize {
+					end := i + shardSize
+					if end > size {
+						end = size
+					}
+					shard := leaves[i:end]
+					summary, err := shard.Summarize(opts...)
+					if err != nil {
+						t.Fatal(err)
+					}
+					shards = append(shards, *summary)
+				}
+
+				// Coordinator
+				rootHash, err := merkletree.CombineShards(shards, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, root.Hash, rootHash, "size=%d shard=%d sorted=%v", size, shardSize, sorted)
+			}
+		}
+	}
+}
+
+// Combine invalid shard summaries
+func TestCombineShards_Invalid(t *testing.T) {
+	_, err := merkletree.CombineShards(nil)
+	if err != nil {
+		t.Log("shards is empty, combine failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test shard size is not a power of 2
+	_, err = merkletree.CombineShards([]merkletree.ShardSummary{
+		{Root: goodHash, Size: 3},
+		{Root: goodHash, Size: 3},
+	})
+	assert.NotNil(t, err)
+
+	// Test shard sizes mismatch
+	_, err = merkletree.CombineShards([]merkletree.ShardSummary{
+		{Root: goodHash, Size: 4},
+		{Root: goodHash, Size: 2},
+		{Root: goodHash, Size: 4},
+	})
+	assert.NotNil(t, err)
+
+	// Test last shard is too large
+	_, err = merkletree.CombineShards([]merkletree.ShardSummary{
+		{Root: goodHash, Size: 4},
+		{Root: goodHash, Size: 5},
+	})
+	assert.NotNil(t, err)
+}
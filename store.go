@@ -0,0 +1,88 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+// PayloadStore content-addressable store of payloads, keyed by hash
+type PayloadStore interface {
+	// Put payload by hash
+	Put(hash []byte, payload []byte) error
+
+	// Get payload by hash
+	Get(hash []byte) ([]byte, error)
+}
+
+// MemoryPayloadStore in-memory payload store, safe for concurrent use
+type MemoryPayloadStore struct {
+	mu       sync.RWMutex
+	payloads map[string][]byte
+}
+
+// NewMemoryPayloadStore returns a new in-memory payload store
+func NewMemoryPayloadStore() *MemoryPayloadStore {
+	return &MemoryPayloadStore{
+		payloads: make(map[string][]byte),
+	}
+}
+
+// Put payload by hash
+func (store *MemoryPayloadStore) Put(hash []byte, payload []byte) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.payloads[string(hash)] = payload
+
+	return nil
+}
+
+// Get payload by hash
+func (store *MemoryPayloadStore) Get(hash []byte) ([]byte, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	payload, ok := store.payloads[string(hash)]
+	if !ok {
+		return nil, errors.New("not found payload")
+	}
+
+	return payload, nil
+}
+
+// FetchPayload returns payload of the leaf, from the store if the leaf
+// holds digest only. Payload from the store is verified against the hash,
+// with the leaf tag configured by WithDomainTags.
+func (node *Leaf) FetchPayload(store PayloadStore, h IHashFunc, opt ...OptionFunc) ([]byte, error) {
+	if node == nil {
+		return nil, errors.New("leaf is empty")
+	} else if node.Payload != nil {
+		return node.Payload, nil
+	}
+
+	payload, err := store.Get(node.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := hashLeaf(h, NewOptions(opt...).LeafTag, payload)
+	if err != nil {
+		return nil, err
+	} else if !bytes.Equal(digest, node.Hash) {
+		return nil, errors.New("payload mismatch hash")
+	}
+
+	return payload, nil
+}
+
+// ProvePayload fetch payload of hash from the store, returns merkle proofs
+// result of it. The payload must match the hash.
+func (tree *Tree) ProvePayload(store PayloadStore, merklePath *PoNs, hash []byte, h IHashFunc, opt ...OptionFunc) (bool, error) {
+	leaf := &Leaf{Hash: hash}
+	if _, err := leaf.FetchPayload(store, h, opt...); err != nil {
+		return false, err
+	}
+
+	return tree.Prove(merklePath, hash, h, opt...)
+}
@@ -0,0 +1,74 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build tree with payload store
+func TestLeaves_BuildTree_WithPayloadStore(t *testing.T) {
+	h := GetCustomHashFunc()
+	store := merkletree.NewMemoryPayloadStore()
+
+	// Build tree, payloads are moved into the store
+	leaves := MockLeaves.Clone()
+	tree1, root1, err := leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithPayloadStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaf := range *leaves {
+		assert.Nil(t, leaf.Payload)
+		assert.NotNil(t, leaf.Hash)
+	}
+
+	// Fetch payload on demand
+	payload, err := (*leaves)[2].FetchPayload(store, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, MockLeaves[2].Payload, payload)
+
+	// Rebuild tree from leaves of digest only
+	tree2, root2, err := leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithPayloadStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *tree1, *tree2)
+	assert.Equal(t, root1.Hash, root2.Hash)
+
+	// Prove payload in the store
+	merklePath, err := tree1.PathForLeaf(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := tree1.ProvePayload(store, &merklePath, goodHash, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, result)
+
+	// Test payload not found
+	_, err = tree1.ProvePayload(store, &merklePath, badHash, h)
+	if err != nil {
+		t.Log("payload not found, prove failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test payload mismatch hash
+	if err := store.Put(badHash, []byte("Hello")); err != nil {
+		t.Fatal(err)
+	}
+	leaf := merkletree.Leaf{Hash: badHash}
+	_, err = leaf.FetchPayload(store, h)
+	if err != nil {
+		t.Log("payload mismatch hash, fetch failed as expected")
+	}
+	assert.NotNil(t, err)
+
+	// Test invalid leaf
+	var invalidLeaf *merkletree.Leaf
+	_, err = invalidLeaf.FetchPayload(store, h)
+	assert.NotNil(t, err)
+}
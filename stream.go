@@ -0,0 +1,100 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// IStreamHashFunc hash interface which hashes message read from reader,
+// which is optional for IHashFunc to hash streamed payload
+type IStreamHashFunc interface {
+	HashReader(r io.Reader) ([]byte, error)
+}
+
+// HashReader returns digest of message read from r until EOF
+func (h *HashFunc) HashReader(r io.Reader) ([]byte, error) {
+	provider := h.Provider()
+	if _, err := io.Copy(provider, r); err != nil {
+		return nil, err
+	}
+
+	return provider.Sum(nil), nil
+}
+
+// HashReader returns digest of digest of message read from r until EOF
+func (h *DoubleHashFunc) HashReader(r io.Reader) ([]byte, error) {
+	first := &HashFunc{Provider: h.Provider}
+
+	digest, err := first.HashReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return first.Hash(digest)
+}
+
+// payloadReader streamed payload of leaf
+type payloadReader struct {
+	r    io.Reader
+	size int64
+
+	// consumed is true once the reader is read by hashing
+	consumed bool
+}
+
+// SetPayloadReader set payload of leaf as reader, which is hashed in a
+// streaming fashion by BuildTree instead of being loaded into Payload. The
+// reader is consumed & released by hashing, it must provide exactly size
+// bytes, or any number of bytes if size is negative. The hash is kept, so
+// the leaf is not hashed again by a later build. The hash function must
+// implement IStreamHashFunc.
+func (node *Leaf) SetPayloadReader(r io.Reader, size int64) {
+	node.Payload = nil
+	node.stream = &payloadReader{r: r, size: size}
+}
+
+// hashPayload returns digest of tag & payload, or of tag & the payload
+// reader if set
+func (node *Leaf) hashPayload(h IHashFunc, tag []byte) ([]byte, error) {
+	if node.stream == nil {
+		return hashLeaf(h, tag, node.Payload)
+	}
+
+	sh, ok := h.(IStreamHashFunc)
+	if !ok {
+		return nil, errors.New("hash function does not support streaming")
+	}
+
+	stream := node.stream
+	if stream.consumed {
+		return nil, errors.New("payload reader is consumed")
+	} else if stream.r == nil {
+		return nil, errors.New("payload reader is not shared by clone")
+	}
+	r := stream.r
+	stream.r = nil
+	stream.consumed = true
+
+	if stream.size < 0 {
+		return sh.HashReader(io.MultiReader(bytes.NewReader(tag), r))
+	}
+
+	// Read one more byte to find out the reader is longer than size
+	lr := &io.LimitedReader{R: r, N: stream.size + 1}
+	digest, err := sh.HashReader(io.MultiReader(bytes.NewReader(tag), lr))
+	if err != nil {
+		return nil, err
+	}
+
+	if read := stream.size + 1 - lr.N; read != stream.size {
+		return nil, &SizeError{Name: "payload size", Expected: uint64(stream.size), Actual: uint64(read)}
+	}
+
+	return digest, nil
+}
+
+// consumed returns true if the payload reader of leaf is consumed by hashing
+func (node *Leaf) consumed() bool {
+	return node.stream != nil && node.stream.consumed
+}
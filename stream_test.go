@@ -0,0 +1,203 @@
+package merkletree_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// notStreamHashFunc hash function without streaming support
+type notStreamHashFunc struct {
+	h merkletree.IHashFunc
+}
+
+func (f *notStreamHashFunc) Hash(msg []byte) ([]byte, error) {
+	return f.h.Hash(msg)
+}
+
+// Build tree of leaves with streamed payload
+func TestLeaf_SetPayloadReader(t *testing.T) {
+	h := GetCustomHashFunc()
+	_, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streamed := MockLeaves.Clone()
+	for i := range *streamed {
+		leaf := &(*streamed)[i]
+		payload := leaf.Payload
+		size := int64(len(payload))
+		if i%2 == 1 {
+			size = -1
+		}
+		leaf.SetPayloadReader(bytes.NewReader(payload), size)
+		assert.Nil(t, leaf.Payload)
+	}
+
+	_, streamedRoot, err := streamed.BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, streamedRoot.Hash)
+
+	// Test builder
+	streamed = MockLeaves.Clone()
+	for i := range *streamed {
+		leaf := &(*streamed)[i]
+		leaf.SetPayloadReader(bytes.NewReader(leaf.Payload), int64(len(leaf.Payload)))
+	}
+	b := merkletree.NewBuilder(merkletree.WithHashFunc(h))
+	if err = b.AddLeaves(streamed); err != nil {
+		t.Fatal(err)
+	}
+	builderRoot, err := b.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, builderRoot)
+
+	// Test double hash
+	leaves := merkletree.Leaves{{Payload: []byte("hello")}}
+	_, root, err = leaves.BuildTree(merkletree.WithProfile(merkletree.ProfileBitcoin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves = merkletree.Leaves{{}}
+	leaves[0].SetPayloadReader(strings.NewReader("hello"), 5)
+	_, streamedRoot, err = leaves.BuildTree(merkletree.WithProfile(merkletree.ProfileBitcoin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, streamedRoot.Hash)
+}
+
+// Streamed payload of wrong size or with unsupported options
+func TestLeaf_SetPayloadReader_Error(t *testing.T) {
+	h := GetCustomHashFunc()
+
+	for _, size := range []int64{4, 6} {
+		leaves := merkletree.Leaves{{}}
+		leaves[0].SetPayloadReader(strings.NewReader("hello"), size)
+		_, _, err := leaves.BuildTree(merkletree.WithHashFunc(h))
+		assert.IsType(t, &merkletree.SizeError{}, err)
+		t.Log("payload size mismatch, build failed as expected, err=", err)
+	}
+
+	leaves := merkletree.Leaves{{}}
+	leaves[0].SetPayloadReader(strings.NewReader("hello"), 5)
+	_, _, err := leaves.BuildTree(merkletree.WithHashFunc(&notStreamHashFunc{h: h}))
+	assert.NotNil(t, err)
+
+	leaves = merkletree.Leaves{{}}
+	leaves[0].SetPayloadReader(strings.NewReader("hello"), 5)
+	_, _, err = leaves.BuildTree(merkletree.WithHashFunc(h), merkletree.WithPayloadStore(merkletree.NewMemoryPayloadStore()))
+	assert.NotNil(t, err)
+}
+
+// Clone leaves of streamed payload
+func TestLeaf_SetPayloadReader_Clone(t *testing.T) {
+	h := GetCustomHashFunc()
+
+	expected, err := h.Hash([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int64{5, -1} {
+		leaves := merkletree.Leaves{{}, {Payload: []byte("world")}}
+		leaves[0].SetPayloadReader(strings.NewReader("hello"), size)
+		clone := leaves.Clone()
+
+		// The clone doesn't share the reader
+		_, _, err := clone.BuildTree(merkletree.WithHashFunc(h))
+		assert.NotNil(t, err)
+		t.Log("reader is not shared, build clone failed as expected, err=", err)
+
+		_, _, err = leaves.BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, leaves[0].Hash)
+
+		// Set a reader on the clone
+		(*clone)[0].SetPayloadReader(strings.NewReader("hello"), size)
+		_, _, err = clone.BuildTree(merkletree.WithHashFunc(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, (*clone)[0].Hash)
+
+		// Clone of hashed leaves has no reader
+		hashed := leaves.Clone()
+		_, _, err = hashed.BuildTree(merkletree.WithHashFunc(h), merkletree.WithSkipHash(true))
+		assert.Nil(t, err)
+	}
+}
+
+// Build tree of streamed payload again
+func TestLeaf_SetPayloadReader_Rebuild(t *testing.T) {
+	h := GetCustomHashFunc()
+	opt := merkletree.WithHashFunc(h)
+
+	leaves := merkletree.Leaves{{}, {}}
+	leaves[0].SetPayloadReader(strings.NewReader("foo"), 3)
+	leaves[1].SetPayloadReader(strings.NewReader("bar"), 3)
+	_, root, err := leaves.BuildTree(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, root2, err := leaves.BuildTree(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, root2.Hash)
+
+	// Test rebuild after add
+	leaves.Add(&merkletree.Leaf{Payload: []byte("baz")})
+	_, root3, err := leaves.BuildTree(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := merkletree.Leaves{{Payload: []byte("foo")}, {Payload: []byte("bar")}, {Payload: []byte("baz")}}
+	_, expectedRoot, err := expected.BuildTree(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expectedRoot.Hash, root3.Hash)
+
+	// Test summarize, the hash is stored in the leaf
+	leaves = merkletree.Leaves{{}, {}}
+	leaves[0].SetPayloadReader(strings.NewReader("foo"), 3)
+	leaves[1].SetPayloadReader(strings.NewReader("bar"), 3)
+	summary, err := leaves.Summarize(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, []byte(summary.Root))
+
+	summary, err = leaves.Summarize(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, []byte(summary.Root))
+
+	_, root2, err = leaves.BuildTree(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, root2.Hash)
+
+	// Test reader consumed without hash, e.g. by a failed build
+	leaves = merkletree.Leaves{{}}
+	leaves[0].SetPayloadReader(strings.NewReader("foo"), 4)
+	_, _, err = leaves.BuildTree(opt)
+	assert.IsType(t, &merkletree.SizeError{}, err)
+	_, _, err = leaves.BuildTree(opt)
+	assert.NotNil(t, err)
+	t.Log("reader is consumed, build failed as expected, err=", err)
+}
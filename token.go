@@ -0,0 +1,208 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ProofTokenVersion1 layout: version byte, uvarint name size, name of hash
+// function, flags byte, uvarint root size, root, then the proof in binary
+const ProofTokenVersion1 byte = 1
+
+// Flags of proof token
+const (
+	// proofTokenSorted sorted pair hashing is used
+	proofTokenSorted byte = 1 << iota
+)
+
+// ProofToken is a complete inclusion evidence, which can be shared as a
+// single URL-safe string
+type ProofToken struct {
+	// Hash is the name of a registered hash function
+	Hash string
+
+	// SortedPairHashing is true if tree is built with sorted pair hashing
+	SortedPairHashing bool
+
+	// Root hash
+	Root Hash
+
+	// Proof of the leaf
+	Proof *Proof
+}
+
+// NewProofToken returns proof token of the leaf at index. hashName is the
+// name of a registered hash function, which the tree is built with. The
+// token does not record the odd rule & node tag, so only OddDuplicate
+// without node tag is supported.
+func (tree *Tree) NewProofToken(index uint64, hashName string, opt ...OptionFunc) (*ProofToken, error) {
+	if _, err := GetHashFunc(hashName); err != nil {
+		return nil, err
+	}
+
+	opts := NewOptions(opt...)
+	if err := opts.checkDuplicate(); err != nil {
+		return nil, err
+	} else if len(opts.NodeTag) != 0 {
+		return nil, errors.New("node tag is not supported by proof token")
+	}
+
+	proof, err := tree.GetProof(index, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := tree.GetRootHash()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProofToken{
+		Hash:              hashName,
+		SortedPairHashing: opts.SortedPairHashing,
+		Root:              root,
+		Proof:             proof,
+	}, nil
+}
+
+// Encode returns token as URL-safe base64 string without padding
+func (token *ProofToken) Encode() (string, error) {
+	if token == nil || token.Proof == nil {
+		return "", errors.New("token is empty")
+	} else if len(token.Hash) > 0xff {
+		return "", errors.New("hash name is too long")
+	}
+
+	proof, err := token.Proof.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	var flags byte
+	if token.SortedPairHashing {
+		flags |= proofTokenSorted
+	}
+
+	buf := []byte{ProofTokenVersion1}
+	buf = appendUvarint(buf, uint64(len(token.Hash)))
+	buf = append(buf, token.Hash...)
+	buf = append(buf, flags)
+	buf = appendUvarint(buf, uint64(len(token.Root)))
+	buf = append(buf, token.Root...)
+	buf = append(buf, proof...)
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// DecodeProofToken decode token from string returned by Encode, the token
+// is rejected if it exceeds limits configured by WithDecodeLimits
+func DecodeProofToken(s string, opt ...OptionFunc) (*ProofToken, error) {
+	limits := NewOptions(opt...).DecodeLimits
+	if err := limits.checkBytes(len(s)); err != nil {
+		return nil, err
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	} else if len(data) == 0 {
+		return nil, errors.New("token is empty")
+	} else if data[0] != ProofTokenVersion1 {
+		return nil, fmt.Errorf("unsupported proof token version %d", data[0])
+	}
+
+	r := bytes.NewReader(data[1:])
+
+	name, err := readSized(r)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	} else if flags&^proofTokenSorted != 0 {
+		return nil, fmt.Errorf("unknown proof token flags %#x", flags)
+	}
+
+	root, err := readSized(r)
+	if err != nil {
+		return nil, err
+	} else if err := limits.checkHashSize(uint64(len(root))); err != nil {
+		return nil, err
+	}
+
+	proof := &Proof{}
+	if err := proof.unmarshal(data[len(data)-r.Len():], limits); err != nil {
+		return nil, err
+	}
+
+	return &ProofToken{
+		Hash:              string(name),
+		SortedPairHashing: flags&proofTokenSorted != 0,
+		Root:              root,
+		Proof:             proof,
+	}, nil
+}
+
+// Verify returns true if the proof leads to root, by the hash function
+// registered by name. Returns *SizeError if a hash of the token is not of
+// the digest size of the hash function.
+func (token *ProofToken) Verify() (bool, error) {
+	if token == nil || token.Proof == nil {
+		return false, errors.New("token is empty")
+	}
+
+	h, err := GetHashFunc(token.Hash)
+	if err != nil {
+		return false, err
+	}
+
+	size, err := hashSize(h)
+	if err != nil {
+		return false, err
+	} else if err := checkDigest("root", token.Root, size); err != nil {
+		return false, err
+	} else if err := token.Proof.checkDigests(size); err != nil {
+		return false, err
+	}
+
+	return token.Proof.Verify(token.Root, h, WithSortedPairHashing(token.SortedPairHashing))
+}
+
+// VerifyProofToken decode token from string within limits configured by
+// WithDecodeLimits & verify it
+func VerifyProofToken(s string, opt ...OptionFunc) (*ProofToken, bool, error) {
+	token, err := DecodeProofToken(s, opt...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := token.Verify()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return token, result, nil
+}
+
+// readSized read uvarint size & the bytes of size from r
+func readSized(r *bytes.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	} else if size > uint64(r.Len()) {
+		return nil, errors.New("invalid size")
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,106 @@
+package merkletree_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Encode & verify proof token
+func TestProofToken(t *testing.T) {
+	h, err := merkletree.GetHashFunc(merkletree.HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sorted := range []bool{false, true} {
+		opt := merkletree.WithSortedPairHashing(sorted)
+		tree, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token, err := tree.NewProofToken(2, merkletree.HashSHA256, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := token.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log("Token=", s)
+		assert.NotContains(t, s, "+")
+		assert.NotContains(t, s, "/")
+		assert.NotContains(t, s, "=")
+
+		decoded, result, err := merkletree.VerifyProofToken(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result)
+		assert.Equal(t, token, decoded)
+		assert.Equal(t, root.Hash, []byte(decoded.Root))
+		assert.Equal(t, goodHash, []byte(decoded.Proof.Leaf))
+
+		// Test tampered root
+		decoded.Root = badHash
+		result, err = decoded.Verify()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, result)
+		t.Log("tampered root, verify token failed as expected")
+	}
+
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test unknown hash function
+	_, err = tree.NewProofToken(2, "unknown")
+	assert.NotNil(t, err)
+
+	token, err := tree.NewProofToken(2, merkletree.HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token.Hash = "unknown"
+	s, err := token.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = merkletree.VerifyProofToken(s)
+	assert.NotNil(t, err)
+
+	// Test invalid tokens
+	for _, s := range []string{"", "!!!", "AA", "AQ", "AQA", "AQAAAA"} {
+		_, err = merkletree.DecodeProofToken(s)
+		assert.NotNil(t, err)
+	}
+
+	// Test tampered index
+	token, err = tree.NewProofToken(2, merkletree.HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token.Proof.Index = 5
+	if s, err = token.Encode(); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = merkletree.VerifyProofToken(s)
+	assert.NotNil(t, err)
+
+	// Test proof of empty hash with a huge path length
+	data := []byte{merkletree.ProofTokenVersion1, 0, 0, 0}
+	data = append(data, merkletree.ProofVersion1, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01)
+	_, _, err = merkletree.VerifyProofToken(base64.RawURLEncoding.EncodeToString(data))
+	assert.NotNil(t, err)
+
+	// Test empty token
+	_, err = (*merkletree.ProofToken)(nil).Encode()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,103 @@
+package merkletree
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Names of traced operations
+const (
+	SpanBuildTree        = "merkletree.BuildTree"
+	SpanProve            = "merkletree.Prove"
+	SpanProveAgainstRoot = "merkletree.ProveAgainstRoot"
+	SpanVerifyProof      = "merkletree.Proof.Verify"
+)
+
+// Keys of span attributes
+const (
+	AttrLeafCount         = "merkletree.leaf_count"
+	AttrPathLength        = "merkletree.path_length"
+	AttrHashFunc          = "merkletree.hash_func"
+	AttrSortedPairHashing = "merkletree.sorted_pair_hashing"
+	AttrResult            = "merkletree.result"
+)
+
+// Attribute key & value of span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts spans of operations, the interface is modeled after
+// OpenTelemetry so an adapter of it is a thin wrapper
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span of an operation, the duration is from Start to End
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// startSpan starts span of operation by the tracer of options, returns nil
+// if tracer is not set
+func (opts *Options) startSpan(name string, attrs ...Attribute) Span {
+	if opts.Tracer == nil {
+		return nil
+	}
+
+	attrs = append(attrs,
+		Attribute{Key: AttrHashFunc, Value: hashFuncName(opts.HashFunc)},
+		Attribute{Key: AttrSortedPairHashing, Value: opts.SortedPairHashing},
+	)
+
+	ctx, span := opts.Tracer.Start(opts.Context, name, attrs...)
+	opts.Context = ctx
+
+	return span
+}
+
+// endSpan records error & ends span, span can be nil
+func endSpan(span Span, err error, attrs ...Attribute) {
+	if span == nil {
+		return
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+	if len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	span.End()
+}
+
+// hashFuncName returns name of h if it's registered, or its type
+func hashFuncName(h IHashFunc) string {
+	if h == nil || !reflect.TypeOf(h).Comparable() {
+		return fmt.Sprintf("%T", h)
+	}
+
+	hashFuncsMu.RLock()
+	defer hashFuncsMu.RUnlock()
+
+	for name, registered := range hashFuncs {
+		if registered == h {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("%T", h)
+}
+
+// pathLength returns length of merkle path, which can be nil
+func pathLength(merklePath *PoNs) int {
+	if merklePath == nil {
+		return 0
+	}
+
+	return len(*merklePath)
+}
@@ -0,0 +1,118 @@
+package merkletree_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordedSpan span recorded by recordingTracer
+type recordedSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...merkletree.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordedSpan) End() {
+	s.ended = true
+}
+
+// recordingTracer tracer recording all spans
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (tracer *recordingTracer) Start(ctx context.Context, name string, attrs ...merkletree.Attribute) (context.Context, merkletree.Span) {
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+
+	span := &recordedSpan{name: name, attrs: make(map[string]interface{})}
+	span.SetAttributes(attrs...)
+	tracer.spans = append(tracer.spans, span)
+
+	return ctx, span
+}
+
+// Trace build & proof operations
+func TestWithTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	h, err := merkletree.GetHashFunc(merkletree.HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := merkletree.WithTracer(tracer)
+
+	tree, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merklePath, err := tree.PathForLeaf(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tree.Prove(&merklePath, goodHash, h, opt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tree.ProveAgainstRoot(&merklePath, badHash, root.Hash, h, opt); err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := tree.GetProof(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = proof.Verify(root.Hash, h, opt); err != nil {
+		t.Fatal(err)
+	}
+
+	// Test error
+	var empty merkletree.Leaves
+	_, _, err = empty.BuildTree(opt)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 5, len(tracer.spans))
+	for _, span := range tracer.spans {
+		assert.True(t, span.ended)
+	}
+
+	build := tracer.spans[0]
+	assert.Equal(t, merkletree.SpanBuildTree, build.name)
+	assert.Equal(t, MockLeaves.Length(), build.attrs[merkletree.AttrLeafCount])
+	assert.Equal(t, merkletree.HashSHA256, build.attrs[merkletree.AttrHashFunc])
+	assert.Equal(t, false, build.attrs[merkletree.AttrSortedPairHashing])
+	assert.Nil(t, build.err)
+
+	prove := tracer.spans[1]
+	assert.Equal(t, merkletree.SpanProve, prove.name)
+	assert.Equal(t, len(merklePath), prove.attrs[merkletree.AttrPathLength])
+	assert.Equal(t, true, prove.attrs[merkletree.AttrResult])
+
+	proveAgainstRoot := tracer.spans[2]
+	assert.Equal(t, merkletree.SpanProveAgainstRoot, proveAgainstRoot.name)
+	assert.Equal(t, false, proveAgainstRoot.attrs[merkletree.AttrResult])
+
+	verify := tracer.spans[3]
+	assert.Equal(t, merkletree.SpanVerifyProof, verify.name)
+	assert.Equal(t, true, verify.attrs[merkletree.AttrResult])
+
+	failed := tracer.spans[4]
+	assert.Equal(t, merkletree.SpanBuildTree, failed.name)
+	assert.NotNil(t, failed.err)
+	t.Log("no leaf, build span recorded error as expected, err=", failed.err)
+}
@@ -0,0 +1,129 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Concatenation orders of a verification step
+const (
+	// OrderSiblingDigest sibling is concatenated before digest
+	OrderSiblingDigest = "sibling||digest"
+
+	// OrderDigestSibling digest is concatenated before sibling
+	OrderDigestSibling = "digest||sibling"
+)
+
+// TranscriptStep one step of proof verification
+type TranscriptStep struct {
+	// Level of the sibling
+	Level uint64 `json:"level"`
+
+	// PoN is the position of the sibling
+	PoN PoN `json:"pon"`
+
+	// Sibling hash
+	Sibling HexBytes `json:"sibling"`
+
+	// Order of concatenation
+	Order string `json:"order"`
+
+	// Digest resulting from the step
+	Digest HexBytes `json:"digest"`
+}
+
+// Transcript human-readable record of how a root is derived from a leaf,
+// can be marshaled as JSON
+type Transcript struct {
+	// Leaf hash
+	Leaf HexBytes `json:"leaf"`
+
+	// Steps from leaf to the root
+	Steps []TranscriptStep `json:"steps"`
+
+	// Root is the expected root hash
+	Root HexBytes `json:"root"`
+
+	// Computed is the root hash derived from the leaf
+	Computed HexBytes `json:"computed"`
+
+	// Result is true if computed root equals to the expected one
+	Result bool `json:"result"`
+}
+
+// Explain verify the proof against root like Verify, returns transcript of
+// each step
+func (proof *Proof) Explain(root []byte, h IHashFunc, opt ...OptionFunc) (*Transcript, error) {
+	steps := make([]TranscriptStep, 0)
+	digest, err := proof.computeRoot(h, NewOptions(opt...), &steps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transcript{
+		Leaf:     proof.Leaf,
+		Steps:    steps,
+		Root:     root,
+		Computed: digest,
+		Result:   bytes.Equal(root, digest),
+	}, nil
+}
+
+// Explain prove like Prove, returns transcript of each step
+func (tree *Tree) Explain(merklePath *PoNs, unverifiedHash []byte, h IHashFunc, opt ...OptionFunc) (*Transcript, error) {
+	if merklePath == nil {
+		return nil, errors.New("path is empty")
+	}
+
+	proof := &Proof{
+		Index: tree.pathIndex(*merklePath),
+		Leaf:  unverifiedHash,
+		Path:  *merklePath,
+	}
+	for i, pon := range *merklePath {
+		sibling, err := tree.GetHash(pon[0], pon[1])
+		if err != nil {
+			return nil, &PathError{Step: i, PoN: pon, Err: err}
+		}
+		proof.Siblings = append(proof.Siblings, sibling)
+	}
+
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		return nil, err
+	}
+
+	return proof.Explain(rootHash, h, opt...)
+}
+
+// pathIndex returns index of the leaf of merkle path, a node paired with
+// itself is the even one
+func (tree *Tree) pathIndex(merklePath PoNs) uint64 {
+	index := uint64(0)
+	for _, pon := range merklePath {
+		if pon[1]%2 == 0 && pon[1]+1 > tree.X(pon[0]) {
+			continue
+		}
+		index |= (pon[1]&1 ^ 1) << pon[0]
+	}
+
+	return index
+}
+
+// String returns transcript as text
+func (transcript *Transcript) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "leaf     %s\n", Hex(transcript.Leaf))
+	for i, step := range transcript.Steps {
+		fmt.Fprintf(&b, "step %d   level=%d sibling=(%d,%d) %s\n", i, step.Level, step.PoN[0], step.PoN[1], Hex(step.Sibling))
+		fmt.Fprintf(&b, "         H(%s) = %s\n", step.Order, Hex(step.Digest))
+	}
+	fmt.Fprintf(&b, "computed %s\n", Hex(transcript.Computed))
+	fmt.Fprintf(&b, "root     %s\n", Hex(transcript.Root))
+	fmt.Fprintf(&b, "result   %v\n", transcript.Result)
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package merkletree_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Explain proof step by step
+func TestTree_Explain(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, sorted := range []bool{false, true} {
+		opt := merkletree.WithSortedPairHashing(sorted)
+		tree, root, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		merklePath, err := tree.PathForLeaf(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		transcript, err := tree.Explain(&merklePath, goodHash, h, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log("Transcript=\n", transcript)
+		assert.True(t, transcript.Result)
+		assert.Equal(t, root.Hash, []byte(transcript.Computed))
+		assert.Equal(t, len(merklePath), len(transcript.Steps))
+		if !sorted {
+			assert.Equal(t, merkletree.OrderDigestSibling, transcript.Steps[0].Order)
+			assert.Equal(t, merkletree.OrderSiblingDigest, transcript.Steps[1].Order)
+		}
+		assert.Equal(t, root.Hash, []byte(transcript.Steps[len(transcript.Steps)-1].Digest))
+
+		data, err := json.Marshal(transcript)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log("TranscriptJSON=", string(data))
+
+		// Test bad hash
+		transcript, err = tree.Explain(&merklePath, badHash, h, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, transcript.Result)
+	}
+
+	// Test invalid path
+	tree, _, err := MockLeaves.Clone().BuildTree(merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tree.Explain(&merkletree.PoNs{{9, 9}}, goodHash, h)
+	assert.NotNil(t, err)
+
+	_, err = tree.Explain(nil, goodHash, h)
+	assert.NotNil(t, err)
+	t.Log("path is nil, explain failed as expected, err=", err)
+}
@@ -0,0 +1,232 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// HexBytes is bytes encoded as hex string in JSON
+type HexBytes []byte
+
+// MarshalText returns hex string of bytes
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+// UnmarshalText decode hex string
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+
+	return nil
+}
+
+// TestVectorOptions options of test vector, only options which can be
+// reproduced by other implementations are included
+type TestVectorOptions struct {
+	// Hash is the name of a registered hash function
+	Hash string `json:"hash"`
+
+	// SortedPairHashing switch
+	SortedPairHashing bool `json:"sortedPairHashing,omitempty"`
+
+	// SingleLeafRoot switch, if true the root of a single leaf is the leaf
+	SingleLeafRoot bool `json:"singleLeafRoot,omitempty"`
+
+	// OddRule is the name of the odd rule, "duplicate" or "promote", empty
+	// means "duplicate"
+	OddRule string `json:"oddRule,omitempty"`
+
+	// LeafTag & NodeTag are the domain tags of leaves & branches
+	LeafTag HexBytes `json:"leafTag,omitempty"`
+	NodeTag HexBytes `json:"nodeTag,omitempty"`
+}
+
+// Names of odd rules in test vectors
+const (
+	vectorOddDuplicate = "duplicate"
+	vectorOddPromote   = "promote"
+)
+
+// TestVectorProof expected proof of a leaf
+type TestVectorProof struct {
+	// Index of the leaf
+	Index uint64 `json:"index"`
+
+	// Leaf hash
+	Leaf HexBytes `json:"leaf"`
+
+	// Path is the merkle path in coordinates
+	Path PoNs `json:"path"`
+
+	// Siblings are the hashes referred by path, from leaf to the root
+	Siblings []HexBytes `json:"siblings"`
+}
+
+// TestVector is a language neutral test vector, with leaves, options,
+// expected root & expected proofs
+type TestVector struct {
+	// Name of the vector
+	Name string `json:"name"`
+
+	// Options to build the tree
+	Options TestVectorOptions `json:"options"`
+
+	// Leaves are the payloads of leaves
+	Leaves []HexBytes `json:"leaves"`
+
+	// Root is the expected root hash
+	Root HexBytes `json:"root"`
+
+	// Proofs are the expected proofs of all leaves
+	Proofs []TestVectorProof `json:"proofs"`
+}
+
+// GenerateTestVector returns test vector of payloads
+func GenerateTestVector(name string, payloads [][]byte, vectorOpts TestVectorOptions) (*TestVector, error) {
+	opts, err := vectorOpts.optionFuncs()
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := make(Leaves, 0, len(payloads))
+	vector := &TestVector{
+		Name:    name,
+		Options: vectorOpts,
+	}
+	for _, payload := range payloads {
+		leaves.Add(&Leaf{Payload: payload})
+		vector.Leaves = append(vector.Leaves, payload)
+	}
+
+	tree, root, err := leaves.BuildTree(opts...)
+	if err != nil {
+		return nil, err
+	}
+	vector.Root = root.Hash
+
+	for index := uint64(0); index < uint64(len(payloads)); index++ {
+		proof, err := tree.testVectorProof(index, opts...)
+		if err != nil {
+			return nil, err
+		}
+		vector.Proofs = append(vector.Proofs, *proof)
+	}
+
+	return vector, nil
+}
+
+// Check rebuild the tree & returns error if root or proofs mismatch
+func (vector *TestVector) Check() error {
+	opts, err := vector.Options.optionFuncs()
+	if err != nil {
+		return err
+	}
+
+	leaves := make(Leaves, 0, len(vector.Leaves))
+	for _, payload := range vector.Leaves {
+		leaves.Add(&Leaf{Payload: payload})
+	}
+
+	tree, root, err := leaves.BuildTree(opts...)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(root.Hash, vector.Root) {
+		return fmt.Errorf("vector '%s': root mismatch, expected %s, got %s", vector.Name, Hex(vector.Root), Hex(root.Hash))
+	}
+
+	for _, expected := range vector.Proofs {
+		actual, err := tree.testVectorProof(expected.Index, opts...)
+		if err != nil {
+			return err
+		}
+
+		actualBytes, err := json.Marshal(actual)
+		if err != nil {
+			return err
+		}
+		expectedBytes, err := json.Marshal(expected)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(actualBytes, expectedBytes) {
+			return fmt.Errorf("vector '%s': proof mismatch at index %d", vector.Name, expected.Index)
+		}
+	}
+
+	return nil
+}
+
+// LoadTestVectors reads JSON array of test vectors
+func LoadTestVectors(r io.Reader) ([]TestVector, error) {
+	var vectors []TestVector
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+// WriteTestVectors writes test vectors as JSON array
+func WriteTestVectors(w io.Writer, vectors []TestVector) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(vectors)
+}
+
+// optionFuncs returns option funcs of test vector options
+func (vectorOpts TestVectorOptions) optionFuncs() ([]OptionFunc, error) {
+	h, err := GetHashFunc(vectorOpts.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var rule OddRule
+	switch vectorOpts.OddRule {
+	case "", vectorOddDuplicate:
+		rule = OddDuplicate
+	case vectorOddPromote:
+		rule = OddPromote
+	default:
+		return nil, fmt.Errorf("unknown odd rule '%s'", vectorOpts.OddRule)
+	}
+
+	return []OptionFunc{
+		WithHashFunc(h),
+		WithSortedPairHashing(vectorOpts.SortedPairHashing),
+		WithSingleLeafRoot(vectorOpts.SingleLeafRoot),
+		WithOddRule(rule),
+		WithDomainTags(vectorOpts.LeafTag, vectorOpts.NodeTag),
+	}, nil
+}
+
+// testVectorProof returns proof of leaf at index
+func (tree *Tree) testVectorProof(index uint64, opt ...OptionFunc) (*TestVectorProof, error) {
+	proof, err := tree.GetProof(index, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	vectorProof := &TestVectorProof{
+		Index:    proof.Index,
+		Leaf:     proof.Leaf,
+		Path:     proof.Path,
+		Siblings: make([]HexBytes, 0, len(proof.Siblings)),
+	}
+	for _, sibling := range proof.Siblings {
+		vectorProof.Siblings = append(vectorProof.Siblings, sibling)
+	}
+
+	return vectorProof, nil
+}
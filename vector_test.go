@@ -0,0 +1,124 @@
+package merkletree_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Check test vectors in testdata
+func TestLoadTestVectors(t *testing.T) {
+	f, err := os.Open("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	vectors, err := merkletree.LoadTestVectors(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, vectors)
+
+	for _, vector := range vectors {
+		if _, err := merkletree.GetHashFunc(vector.Options.Hash); err != nil {
+			t.Log("skip vector", vector.Name, err)
+			continue
+		}
+		assert.NoError(t, vector.Check(), vector.Name)
+	}
+}
+
+// Generate test vector & write/load it again
+func TestGenerateTestVector(t *testing.T) {
+	payloads := [][]byte{[]byte("Hello"), []byte("你好"), []byte("Hola")}
+	vector, err := merkletree.GenerateTestVector("simple", payloads, merkletree.TestVectorOptions{
+		Hash: merkletree.HashSHA256,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, vector.Check())
+	assert.Equal(t, len(payloads), len(vector.Proofs))
+	assert.Equal(t, goodHash, []byte(vector.Proofs[1].Leaf))
+
+	// Write & load
+	var buf bytes.Buffer
+	if err := merkletree.WriteTestVectors(&buf, []merkletree.TestVector{*vector}); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Vectors=", buf.String())
+	vectors, err := merkletree.LoadTestVectors(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, *vector, vectors[0])
+
+	// Test root mismatch
+	vector.Root = badHash
+	err = vector.Check()
+	if err != nil {
+		t.Log("root is bad, check failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	// Test single leaf as the root
+	vector, err = merkletree.GenerateTestVector("single", payloads[:1], merkletree.TestVectorOptions{
+		Hash:           merkletree.HashSHA256,
+		SingleLeafRoot: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, vector.Check())
+	assert.Equal(t, vector.Proofs[0].Leaf, vector.Root)
+	assert.Empty(t, vector.Proofs[0].Path)
+
+	// Test odd rule & domain tags
+	vector, err = merkletree.GenerateTestVector("promote", payloads, merkletree.TestVectorOptions{
+		Hash:    merkletree.HashSHA256,
+		OddRule: "promote",
+		LeafTag: []byte{0},
+		NodeTag: []byte{1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, vector.Check())
+	h, err := merkletree.GetHashFunc(merkletree.HashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var leaves merkletree.Leaves
+	for _, payload := range payloads {
+		leaves.Add(&merkletree.Leaf{Payload: payload})
+	}
+	_, root, err := leaves.BuildTree(
+		merkletree.WithHashFunc(h),
+		merkletree.WithOddRule(merkletree.OddPromote),
+		merkletree.WithDomainTags([]byte{0}, []byte{1}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, root.Hash, []byte(vector.Root))
+
+	// Test unknown odd rule
+	_, err = merkletree.GenerateTestVector("unknown", payloads, merkletree.TestVectorOptions{
+		Hash:    merkletree.HashSHA256,
+		OddRule: "unknown",
+	})
+	if err != nil {
+		t.Log("odd rule is unknown, generate test vector failed as expected, err=", err)
+	}
+	assert.NotNil(t, err)
+
+	// Test unknown hash function
+	_, err = merkletree.GenerateTestVector("unknown", payloads, merkletree.TestVectorOptions{
+		Hash: "unknown",
+	})
+	assert.NotNil(t, err)
+}
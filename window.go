@@ -0,0 +1,188 @@
+package merkletree
+
+import (
+	"errors"
+)
+
+// Window merkle tree over the most recent leaves of a fixed capacity. The
+// leaves are kept in a ring of slots, appending a leaf to a full window
+// evicts the oldest one, and only the hashes from the slot to the root are
+// recomputed. The root is the same as the one built by BuildTree with the
+// leaf hashes in slot order, which is returned by Tree.
+type Window struct {
+	opts     Options
+	capacity uint64
+	count    uint64
+	tree     Tree
+
+	// digest size of hash function
+	size int
+}
+
+// NewWindow returns a new window of capacity
+func NewWindow(capacity int, opt ...OptionFunc) (*Window, error) {
+	if capacity <= 0 {
+		return nil, errors.New("invalid capacity")
+	}
+
+	opts := NewOptions(opt...)
+	if err := opts.checkBinary(); err != nil {
+		return nil, err
+	} else if err := opts.checkDuplicate(); err != nil {
+		return nil, err
+	}
+
+	size, err := opts.digestSize()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Window{
+		opts:     opts,
+		capacity: uint64(capacity),
+		size:     size,
+	}, nil
+}
+
+// Capacity returns capacity of window
+func (w *Window) Capacity() int {
+	return int(w.capacity)
+}
+
+// Len returns number of leaves in window
+func (w *Window) Len() int {
+	if w.count < w.capacity {
+		return int(w.count)
+	}
+
+	return int(w.capacity)
+}
+
+// Count returns number of leaves appended, including the evicted ones
+func (w *Window) Count() uint64 {
+	return w.count
+}
+
+// Append hash the payload & append it as a leaf
+func (w *Window) Append(payload []byte) error {
+	digest, err := hashLeaf(w.opts.HashFunc, w.opts.LeafTag, payload)
+	if err != nil {
+		return err
+	}
+
+	return w.AppendHash(digest)
+}
+
+// AppendHash append a leaf by hash, the oldest leaf is evicted if window
+// is full. Returns *SizeError if hash is not of the digest size of hash
+// function.
+func (w *Window) AppendHash(hash Hash) error {
+	if err := w.opts.Context.Err(); err != nil {
+		return err
+	} else if err := checkDigest("leaf", hash, w.size); err != nil {
+		return err
+	}
+
+	slot := w.count % w.capacity
+	if w.count < w.capacity {
+		w.grow()
+	}
+
+	if err := w.set(slot, hash); err != nil {
+		return err
+	}
+	w.count++
+
+	return nil
+}
+
+// Root returns root hash of leaves in window, the empty hash is returned
+// without leaves if AllowEmpty is set
+func (w *Window) Root() (Hash, error) {
+	if w.count == 0 {
+		if !w.opts.AllowEmpty {
+			return nil, errors.New("not found leaf")
+		}
+		return w.opts.emptyHash()
+	} else if w.Len() == 1 && w.opts.SingleLeafRoot {
+		return w.tree[0][0], nil
+	}
+
+	return w.tree.GetRootHash()
+}
+
+// Slot returns index of the leaf in Tree, seq is the sequence number of the
+// leaf in appending order from 0. Returns error if it's evicted.
+func (w *Window) Slot(seq uint64) (uint64, error) {
+	if seq >= w.count || w.count-seq > w.capacity {
+		return 0, &IndexError{Index: seq, Count: w.count}
+	}
+
+	return seq % w.capacity, nil
+}
+
+// Tree returns a copy of tree of leaves in slot order
+func (w *Window) Tree() *Tree {
+	if w.Len() == 1 && w.opts.SingleLeafRoot {
+		return &Tree{{w.tree[0][0]}}
+	}
+
+	return w.tree.Clone()
+}
+
+// grow reshape the tree for one more leaf, hashes of the new positions are
+// computed by set
+func (w *Window) grow() {
+	width := w.count + 1
+	if width%2 == 1 {
+		width++
+	}
+
+	for y := 0; ; y++ {
+		if y == len(w.tree) {
+			w.tree = append(w.tree, nil)
+		}
+		for uint64(len(w.tree[y])) < width {
+			w.tree[y] = append(w.tree[y], nil)
+		}
+
+		if width == 1 {
+			break
+		}
+		width = (width + 1) / 2
+	}
+}
+
+// set the leaf hash of slot & recompute hashes up to the root
+func (w *Window) set(slot uint64, hash Hash) error {
+	leaves := w.tree[0]
+	leaves[slot] = hash
+
+	// The last leaf is duplicated if number of leaves is odd
+	size := w.count + 1
+	if size > w.capacity {
+		size = w.capacity
+	}
+	if size%2 == 1 && slot == size-1 {
+		leaves[size] = hash
+	}
+
+	x := slot
+	for y := 0; y < len(w.tree)-1; y++ {
+		level := w.tree[y]
+		left, right := x&^1, x|1
+		if right >= uint64(len(level)) {
+			right = left
+		}
+
+		digest, err := hashPair(w.opts.HashFunc, w.opts.NodeTag, level[left], level[right], w.opts.SortedPairHashing)
+		if err != nil {
+			return err
+		}
+
+		x /= 2
+		w.tree[y+1][x] = digest
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package merkletree_test
+
+import (
+	"testing"
+
+	"github.com/jovijovi/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+// Append leaves to window, compare with BuildTree
+func TestWindow_Append(t *testing.T) {
+	h := GetCustomHashFunc()
+	for _, sorted := range []bool{false, true} {
+		for capacity := 1; capacity <= 9; capacity++ {
+			opt := merkletree.WithSortedPairHashing(sorted)
+			w, err := merkletree.NewWindow(capacity, merkletree.WithHashFunc(h), opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Leaf hashes in slot order
+			slots := make([]merkletree.Hash, 0, capacity)
+			for i := 0; i < 3*capacity; i++ {
+				payload := []byte{byte(i)}
+				if err = w.Append(payload); err != nil {
+					t.Fatal(err)
+				}
+
+				digest, _ := h.Hash(payload)
+				if len(slots) < capacity {
+					slots = append(slots, digest)
+				} else {
+					slots[i%capacity] = digest
+				}
+
+				leaves := make(merkletree.Leaves, 0, len(slots))
+				for _, hash := range slots {
+					leaves.Add(&merkletree.Leaf{Hash: hash})
+				}
+				tree, root, err := leaves.BuildTree(merkletree.WithHashFunc(h), opt, merkletree.WithSkipHash(true))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rootHash, err := w.Root()
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, root.Hash, rootHash)
+				assert.Equal(t, tree, w.Tree())
+				assert.Equal(t, len(slots), w.Len())
+			}
+			assert.Equal(t, capacity, w.Capacity())
+			assert.Equal(t, uint64(3*capacity), w.Count())
+		}
+	}
+}
+
+// Find slot of leaf by sequence number & prove it
+func TestWindow_Slot(t *testing.T) {
+	h := GetCustomHashFunc()
+	w, err := merkletree.NewWindow(5, merkletree.WithHashFunc(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = w.Root()
+	assert.NotNil(t, err)
+
+	for i := 0; i < 12; i++ {
+		if err = w.Append([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root, err := w.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := w.Tree()
+	for seq := uint64(7); seq < 12; seq++ {
+		slot, err := w.Slot(seq)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		proof, err := tree.GetProof(slot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest, _ := h.Hash([]byte{byte(seq)})
+		assert.Equal(t, digest, []byte(proof.Leaf))
+
+		result, err := proof.Verify(root, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result)
+	}
+
+	// Test evicted & not appended leaves
+	for _, seq := range []uint64{6, 12} {
+		_, err = w.Slot(seq)
+		assert.IsType(t, &merkletree.IndexError{}, err)
+	}
+
+	// Test invalid capacity
+	_, err = merkletree.NewWindow(0)
+	assert.NotNil(t, err)
+}